@@ -4,7 +4,11 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"html"
+	"io"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
@@ -13,16 +17,21 @@ import (
 	"google.golang.org/grpc/status"
 
 	grpcHn "hackernews/generated"
+	"hackernews/tracing"
 )
 
-func GetTopStories(client *grpcHn.HnServiceClient, context *context.Context, maxStoriesCount *int) {
+func GetTopStories(client *grpcHn.HnServiceClient, context *context.Context, maxStoriesCount *int, page *int, pageToken *string) {
 	
 	if *maxStoriesCount <= 0 {
 		fmt.Println("Stories number to fetch must be a positive number")
 		return
+	} else if *page < 0 {
+		fmt.Println("Page number must be a positive number")
+		return
 	}
 
-	request := grpcHn.TopStoriesRequest{StoryNumber: uint32(*maxStoriesCount)}
+	offset, pageSize := pageBounds(*maxStoriesCount, *page, *pageToken)
+	request := grpcHn.TopStoriesRequest{StoryNumber: uint32(*maxStoriesCount), Offset: offset, PageSize: pageSize, PageToken: *pageToken}
 	topStories, err := (*client).GetTopStories(*context, &request)
     
 	if err != nil {
@@ -35,13 +44,220 @@ func GetTopStories(client *grpcHn.HnServiceClient, context *context.Context, max
 		}
 	}
 	
-	for _, story := range topStories.Stories {
-		fmt.Printf("- %s\n", story.Title)
-		fmt.Printf("  %s\n", story.Url)
-		fmt.Println()
+	printStories(topStories.Stories)
+	printRankingFetchTime(topStories.GetRankingFetchedAt())
+	printStaleness(topStories.GetStale(), topStories.GetStaleAgeSeconds())
+	printNextPage(topStories.GetNextPageToken())
+}
+
+func GetStories(client *grpcHn.HnServiceClient, context *context.Context, kind grpcHn.StoryListKind, maxStoriesCount *int, page *int, pageToken *string) {
+	if *maxStoriesCount <= 0 {
+		fmt.Println("Stories number to fetch must be a positive number")
+		return
+	} else if *page < 0 {
+		fmt.Println("Page number must be a positive number")
+		return
+	}
+
+	offset, pageSize := pageBounds(*maxStoriesCount, *page, *pageToken)
+	request := grpcHn.StoriesRequest{Kind: kind, StoryNumber: uint32(*maxStoriesCount), Offset: offset, PageSize: pageSize, PageToken: *pageToken}
+	stories, err := (*client).GetStories(*context, &request)
+
+	if status.Code(err) == codes.DeadlineExceeded {
+		fmt.Printf("Server took too long to answer the request. You can consider adding more timeout with the -%s flag\n", timeoutFlag)
+		return
+	} else if err != nil {
+		fmt.Printf("Error: %v\n", err.Error())
+		return
+	}
+
+	printStories(stories.Stories)
+	printRankingFetchTime(stories.GetRankingFetchedAt())
+	printStaleness(stories.GetStale(), stories.GetStaleAgeSeconds())
+	printNextPage(stories.GetNextPageToken())
+}
+
+// Stories are paginated with the max number of stories as page size once a page number or token is provided.
+// Pages are numbered from 1, and no page number means no pagination
+func pageBounds(maxStoriesCount int, page int, pageToken string) (offset uint32, pageSize uint32) {
+	if page == 0 && pageToken == "" {
+		return 0, 0
+	}
+
+	return uint32(max(page - 1, 0) * maxStoriesCount), uint32(maxStoriesCount)
+}
+
+// The proxy caches rankings briefly, so stories may be ranked as they were a few seconds ago
+func printRankingFetchTime(fetchedAt int64) {
+	if fetchedAt > 0 {
+		fmt.Printf("Ranked as of %s\n", time.Unix(fetchedAt, 0).Format(time.TimeOnly))
+	}
+}
+
+// Stale stories were served by the proxy from the last ones it fetched, HackerNews API being unreachable
+func printStaleness(stale bool, ageSeconds uint32) {
+	if stale {
+		fmt.Printf("HackerNews is unreachable, these stories may be outdated: they were fetched %s ago\n", time.Duration(ageSeconds) * time.Second)
+	}
+}
+
+func printNextPage(nextPageToken string) {
+	if nextPageToken != "" {
+		fmt.Printf("Next page can be fetched with: -%s %s\n", pageTokenFlag, nextPageToken)
+	}
+}
+
+func GetCommentTree(client *grpcHn.HnServiceClient, context *context.Context, itemId *int, maxDepth *int, maxChildren *int) {
+	if *maxDepth < 0 || *maxChildren < 0 {
+		fmt.Println("Comments depth and children number must be positive numbers")
+		return
+	}
+
+	request := grpcHn.CommentTreeRequest{Id: int64(*itemId), MaxDepth: uint32(*maxDepth), MaxChildren: uint32(*maxChildren)}
+	tree, err := (*client).GetCommentTree(*context, &request)
+
+	if status.Code(err) == codes.NotFound {
+		fmt.Printf("Item '%d' does not exist in HackerNews\n", *itemId)
+		return
+	} else if status.Code(err) == codes.DeadlineExceeded {
+		fmt.Printf("Server took too long to answer the request. You can consider adding more timeout with the -%s flag\n", timeoutFlag)
+		return
+	} else if err != nil {
+		fmt.Printf("Error: %v\n", err.Error())
+		return
+	}
+
+	root := tree.GetItem()
+	fmt.Printf("%s\n", root.GetTitle())
+	fmt.Printf("%s\n", root.GetUrl())
+	fmt.Println()
+
+	for _, child := range tree.GetChildren() {
+		printCommentTree(child, 0)
+	}
+}
+
+// Prints a comment and its replies, indented according to their depth in the discussion
+func printCommentTree(tree *grpcHn.CommentTree, depth int) {
+	indent := strings.Repeat("    ", depth)
+	comment := tree.GetItem()
+
+	fmt.Printf("%s%s (%s)\n", indent, comment.GetBy(), time.Unix(comment.GetCreatedAt(), 0).Format(time.DateTime))
+	for _, line := range strings.Split(formatCommentText(comment.GetText()), "\n") {
+		fmt.Printf("%s%s\n", indent, line)
+	}
+	fmt.Println()
+
+	for _, child := range tree.GetChildren() {
+		printCommentTree(child, depth + 1)
+	}
+}
+
+// Comments text is HTML, only paragraphs and escaped characters are rendered for the terminal
+func formatCommentText(text string) string {
+	return html.UnescapeString(strings.ReplaceAll(text, "<p>", "\n"))
+}
+
+// Prints top stories progressively while the server streams them.
+// Stories arrive in any order, so a story is held back until every better ranked one has been printed
+func StreamTopStories(client *grpcHn.HnServiceClient, context *context.Context, maxStoriesCount *int) {
+	if *maxStoriesCount <= 0 {
+		fmt.Println("Stories number to fetch must be a positive number")
+		return
+	}
+
+	request := grpcHn.TopStoriesRequest{StoryNumber: uint32(*maxStoriesCount)}
+	stream, err := (*client).StreamTopStories(*context, &request)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err.Error())
+		return
+	}
+
+	pendingStories := make(map[uint32]*grpcHn.Story)
+	var nextRank uint32 = 1
+
+	for {
+		rankedStory, err := stream.Recv()
+
+		if err == io.EOF {
+			if fetchedAt := stream.Trailer().Get(rankingFetchedAtTrailer); len(fetchedAt) > 0 {
+				fetchedAtSeconds, _ := strconv.ParseInt(fetchedAt[0], 10, 64)
+				printRankingFetchTime(fetchedAtSeconds)
+			}
+			if staleAge := stream.Trailer().Get(staleAgeTrailer); len(staleAge) > 0 {
+				ageSeconds, _ := strconv.Atoi(staleAge[0])
+				printStaleness(true, uint32(ageSeconds))
+			}
+			return
+		} else if status.Code(err) == codes.DeadlineExceeded {
+			fmt.Printf("Server took too long to answer the request. You can consider adding more timeout with the -%s flag\n", timeoutFlag)
+			return
+		} else if err != nil {
+			fmt.Printf("Error: %v\n", err.Error())
+			return
+		}
+
+		pendingStories[rankedStory.GetRank()] = rankedStory.GetStory()
+
+		for story, isReady := pendingStories[nextRank]; isReady; story, isReady = pendingStories[nextRank] {
+			printStory(story)
+			delete(pendingStories, nextRank)
+			nextRank++
+		}
+	}
+}
+
+// Prints front page changes as they are streamed by the server, until the user stops the client
+func WatchTopStories(client *grpcHn.HnServiceClient, context *context.Context) {
+	stream, err := (*client).WatchTopStories(*context, &grpcHn.WatchTopStoriesRequest{})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err.Error())
+		return
+	}
+
+	for {
+		event, err := stream.Recv()
+
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			fmt.Printf("Error: %v\n", err.Error())
+			return
+		}
+
+		printFrontPageEvent(event)
+	}
+}
+
+func printFrontPageEvent(event *grpcHn.FrontPageEvent) {
+	story := event.GetStory()
+	now := time.Now().Format(time.TimeOnly)
+
+	switch event.GetKind() {
+	case grpcHn.FrontPageEventKind_STORY_ENTERED:
+		fmt.Printf("[%s] #%d entered: %s (%d points)\n", now, event.GetRank(), story.GetTitle(), story.GetScore())
+	case grpcHn.FrontPageEventKind_STORY_LEFT:
+		fmt.Printf("[%s] #%d left: %s\n", now, event.GetPreviousRank(), story.GetTitle())
+	case grpcHn.FrontPageEventKind_STORY_MOVED:
+		fmt.Printf("[%s] #%d -> #%d: %s\n", now, event.GetPreviousRank(), event.GetRank(), story.GetTitle())
+	case grpcHn.FrontPageEventKind_SCORE_CHANGED:
+		fmt.Printf("[%s] #%d %d -> %d points: %s\n", now, event.GetRank(), event.GetPreviousScore(), story.GetScore(), story.GetTitle())
 	}
 }
 
+func printStories(stories []*grpcHn.Story) {
+	for _, story := range stories {
+		printStory(story)
+	}
+}
+
+func printStory(story *grpcHn.Story) {
+	fmt.Printf("- %s\n", story.Title)
+	fmt.Printf("  %s\n", story.Url)
+	fmt.Printf("  %d points by %s on %s | %d comments\n", story.GetScore(), story.GetBy(), time.Unix(story.GetCreatedAt(), 0).Format(time.DateTime), story.GetDescendants())
+	fmt.Println()
+}
+
 func GetUserInfo(client *grpcHn.HnServiceClient, context *context.Context, userName *string) {
 	if *userName == "" {
 		fmt.Println("Please provide a username to fetch user details")
@@ -68,52 +284,241 @@ func GetUserInfo(client *grpcHn.HnServiceClient, context *context.Context, userN
 	fmt.Printf("Joined: %s\n", time.Unix(user.GetJoinedAt(), 0).Format(time.DateOnly))
 }
 
+// Prints usage statistics of every cache of the proxy
+func GetCacheStats(client *grpcHn.HnAdminServiceClient, context *context.Context) {
+	statsList, err := (*client).GetCacheStats(*context, &grpcHn.CacheStatsRequest{})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err.Error())
+		return
+	}
+
+	for _, stats := range statsList.GetCaches() {
+		lookups := stats.GetHits() + stats.GetMisses()
+		hitRatio := 0.0
+		if lookups > 0 {
+			hitRatio = float64(stats.GetHits()) / float64(lookups) * 100
+		}
+
+		fmt.Printf("Cache:        %s\n", stats.GetName())
+		fmt.Printf("Hits:         %d (%.1f%%)\n", stats.GetHits(), hitRatio)
+		fmt.Printf("Misses:       %d\n", stats.GetMisses())
+		fmt.Printf("Evictions:    %d\n", stats.GetEvictions())
+		fmt.Printf("Expirations:  %d\n", stats.GetExpirations())
+		fmt.Printf("Size:         %d\n", stats.GetSize())
+		fmt.Printf("Oldest entry: %s\n", time.Duration(stats.GetOldestEntryAgeSeconds()) * time.Second)
+		fmt.Println()
+	}
+}
+
+// Removes a user or an item from the proxy cache, so that its next request fetches it again from HackerNews
+func Invalidate(client *grpcHn.HnAdminServiceClient, context *context.Context, userName string, itemId int) {
+	var err error
+
+	if userName != "" {
+		_, err = (*client).InvalidateUser(*context, &grpcHn.InvalidateUserRequest{Name: userName})
+	} else {
+		_, err = (*client).InvalidateItem(*context, &grpcHn.InvalidateItemRequest{Id: int64(itemId)})
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err.Error())
+		return
+	}
+
+	fmt.Println("Invalidated")
+}
+
+// Removes every entry of the named cache, or of every cache when no name is provided
+func PurgeCache(client *grpcHn.HnAdminServiceClient, context *context.Context, cacheName *string) {
+	result, err := (*client).PurgeCache(*context, &grpcHn.PurgeCacheRequest{Name: *cacheName})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Purged %d entries\n", result.GetPurgedEntryCount())
+}
+
+func ListCacheKeys(client *grpcHn.HnAdminServiceClient, context *context.Context, cacheName *string, maxKeys *int) {
+	if *cacheName == "" {
+		fmt.Printf("Please provide the name of the cache to list keys of with the -%s flag\n", cacheFlag)
+		return
+	}
+
+	cacheKeys, err := (*client).ListCacheKeys(*context, &grpcHn.ListCacheKeysRequest{Name: *cacheName, MaxKeys: uint32(*maxKeys)})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err.Error())
+		return
+	}
+
+	for _, key := range cacheKeys.GetKeys() {
+		fmt.Println(key)
+	}
+	fmt.Printf("%d of %d keys\n", len(cacheKeys.GetKeys()), cacheKeys.GetTotalKeyCount())
+}
+
 const serverAddress = "localhost:50051"
+// Address of the admin service, which the server only serves on localhost once started with -admin-port 50052
+const adminServerAddress = "localhost:50052"
+
+// Trailer the server sets on streams of stale stories, with the time in seconds since the oldest of them was fetched
+const staleAgeTrailer string = "hnproxy-stale-age-seconds"
+// Trailer the server sets on streams of stories, with the unix time in seconds when their ranking was fetched
+const rankingFetchedAtTrailer string = "hnproxy-ranking-fetched-at"
 
 const listFlag string = "list"
+const streamFlag string = "stream"
+const watchFlag string = "watch"
+const newFlag string = "new"
+const bestFlag string = "best"
+const askFlag string = "ask"
+const showFlag string = "show"
+const jobsFlag string = "jobs"
 const newsNumberFlag string = "max"
+const pageFlag string = "page"
+const pageTokenFlag string = "page-token"
 const timeoutFlag string = "timeout"
 const whoisFlag string = "whois"
+const commentsFlag string = "comments"
+const depthFlag string = "depth"
+const childrenFlag string = "children"
+const cacheStatsFlag string = "cache-stats"
+const invalidateUserFlag string = "invalidate-user"
+const invalidateItemFlag string = "invalidate-item"
+const purgeFlag string = "purge"
+const cacheKeysFlag string = "cache-keys"
+const cacheFlag string = "cache"
+const tracesExporterFlag string = "traces-exporter"
+const otlpEndpointFlag string = "otlp-endpoint"
 
 var (
     userName = flag.String(whoisFlag, "", "Retrieve information on user passed as input")
     isListMode = flag.Bool(listFlag, false, "Number of top news from HackerNews front page to fetch")
-    newsNumber = flag.Int(newsNumberFlag, 10, fmt.Sprintf("Max number of news to fetch. Must be used along with the -%s flag", listFlag))
+    isStreamed = flag.Bool(streamFlag, false, fmt.Sprintf("Print top stories as soon as they are fetched. Must be used along with the -%s flag", listFlag))
+    isWatchMode = flag.Bool(watchFlag, false, "Prints front page changes as they happen, until the client is stopped")
+    isNewMode = flag.Bool(newFlag, false, "Fetches newest stories")
+    isBestMode = flag.Bool(bestFlag, false, "Fetches best stories")
+    isAskMode = flag.Bool(askFlag, false, "Fetches latest Ask HN stories")
+    isShowMode = flag.Bool(showFlag, false, "Fetches latest Show HN stories")
+    isJobsMode = flag.Bool(jobsFlag, false, "Fetches latest job offers")
+    newsNumber = flag.Int(newsNumberFlag, 10, fmt.Sprintf("Max number of news to fetch, or of keys to list. Must be used along with one of the -%s, -%s, -%s, -%s, -%s, -%s or -%s flags", listFlag, newFlag, bestFlag, askFlag, showFlag, jobsFlag, cacheKeysFlag))
+    commentsItemId = flag.Int(commentsFlag, 0, "Retrieve comments of the item (story, poll...) whose id is passed as input")
+    commentsDepth = flag.Int(depthFlag, 3, fmt.Sprintf("Max depth of replies to fetch. Must be used along with the -%s flag", commentsFlag))
+    commentsChildren = flag.Int(childrenFlag, 10, fmt.Sprintf("Max number of replies to fetch per comment. Must be used along with the -%s flag", commentsFlag))
+    page = flag.Int(pageFlag, 0, fmt.Sprintf("Page of stories to fetch, starting from 1. Pages contain as many stories as the -%s flag", newsNumberFlag))
+    pageToken = flag.String(pageTokenFlag, "", "Token of the page to fetch, printed along with the previous page. Pages fetched with tokens keep the ranking of the first page")
+    isCacheStatsMode = flag.Bool(cacheStatsFlag, false, "Prints usage statistics of the proxy caches")
+    invalidatedUserName = flag.String(invalidateUserFlag, "", "Removes user passed as input from the proxy cache")
+    invalidatedItemId = flag.Int(invalidateItemFlag, 0, "Removes item whose id is passed as input from the proxy cache")
+    isPurgeMode = flag.Bool(purgeFlag, false, fmt.Sprintf("Removes every entry of the proxy caches, or only of the cache named by the -%s flag", cacheFlag))
+    isCacheKeysMode = flag.Bool(cacheKeysFlag, false, fmt.Sprintf("Lists keys of the cache named by the -%s flag", cacheFlag))
+    cacheName = flag.String(cacheFlag, "", fmt.Sprintf("Name of the cache to administrate: users, stories or rankings. Must be used along with the -%s or -%s flags", purgeFlag, cacheKeysFlag))
     timeoutSeconds = flag.Int(timeoutFlag, 20, "Timeout in seconds before client cutting connection to server")
+    tracesExporter = flag.String(tracesExporterFlag, tracing.NoExporter, "Where spans of requests are exported: none, stdout or otlp. Trace context is sent to the server in any case")
+    otlpEndpoint = flag.String(otlpEndpointFlag, "localhost:4317", fmt.Sprintf("Address of the OTLP collector spans are sent to. Must be used along with -%s otlp", tracesExporterFlag))
 )
 
+// Connection sending the trace context of requests, established on first use
+func newConnection(address string) (*grpc.ClientConn, error) {
+	return grpc.NewClient(address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(tracing.StreamClientInterceptor()),
+	)
+}
+
 func main() {
     flag.Parse()
 
 	var isUserMode bool = *userName != ""
+	var isCommentsMode bool = *commentsItemId != 0
+	var isInvalidateMode bool = *invalidatedUserName != "" || *invalidatedItemId != 0
+
+	storyListModes := map[grpcHn.StoryListKind]bool{
+		grpcHn.StoryListKind_NEW_STORIES: *isNewMode,
+		grpcHn.StoryListKind_BEST_STORIES: *isBestMode,
+		grpcHn.StoryListKind_ASK_STORIES: *isAskMode,
+		grpcHn.StoryListKind_SHOW_STORIES: *isShowMode,
+		grpcHn.StoryListKind_JOB_STORIES: *isJobsMode,
+	}
+
+	selectedModesCount := 0
+	var selectedStoryList *grpcHn.StoryListKind
+	for _, isModeSelected := range []bool{*isListMode, isUserMode, isCommentsMode, *isWatchMode, *isCacheStatsMode, *invalidatedUserName != "", *invalidatedItemId != 0, *isPurgeMode, *isCacheKeysMode} {
+		if isModeSelected {
+			selectedModesCount++
+		}
+	}
+	for kind, isModeSelected := range storyListModes {
+		if isModeSelected {
+			selectedModesCount++
+			selectedStoryList = &kind
+		}
+	}
 
-    if !*isListMode && !isUserMode {
-        fmt.Printf("Use at least and only one argument among -%s and -%s\n", listFlag, whoisFlag)
+	modeFlags := fmt.Sprintf("-%s, -%s, -%s, -%s, -%s, -%s, -%s, -%s, -%s, -%s, -%s, -%s, -%s and -%s", listFlag, newFlag, bestFlag, askFlag, showFlag, jobsFlag, whoisFlag, commentsFlag, watchFlag, cacheStatsFlag, invalidateUserFlag, invalidateItemFlag, purgeFlag, cacheKeysFlag)
+
+    if selectedModesCount == 0 {
+        fmt.Printf("Use at least and only one argument among %s\n", modeFlags)
         flag.PrintDefaults()
         return
-    } else if *isListMode && isUserMode {
-        fmt.Printf("Arguments -%s and -%s cannot be used together\n", listFlag, whoisFlag)
+    } else if selectedModesCount > 1 {
+        fmt.Printf("Arguments %s cannot be used together\n", modeFlags)
         flag.PrintDefaults()
         return
     }
 
+	shutdownTracing, err := tracing.Setup(*tracesExporter, *otlpEndpoint, "hackernews-client")
+	if err != nil {
+		log.Fatalf("Cannot set up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
     // Set up a connection to the server.
-    conn, err := grpc.NewClient(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+    conn, err := newConnection(serverAddress)
     if err != nil {
         log.Fatalf("Cannot connect to server: %v", err)
     }
     defer conn.Close()
+
+	adminConn, err := newConnection(adminServerAddress)
+	if err != nil {
+		log.Fatalf("Cannot connect to server admin service: %v", err)
+	}
+	defer adminConn.Close()
     
 	client := grpcHn.NewHnServiceClient(conn)
+	adminClient := grpcHn.NewHnAdminServiceClient(adminConn)
 
 	maxTimeToWait := time.Duration(*timeoutSeconds) * time.Second
 	ctx, cancel := context.WithTimeout(context.Background(), maxTimeToWait)
     defer cancel()
 
-    if *isListMode {
-        GetTopStories(&client, &ctx, newsNumber)
+    if *isWatchMode {
+        // watching lasts until the user stops the client, so it is not bound to the timeout
+        watchCtx := context.Background()
+        WatchTopStories(&client, &watchCtx)
+        return
+    }
+
+    if *isListMode && *isStreamed {
+        StreamTopStories(&client, &ctx, newsNumber)
+    } else if *isListMode {
+        GetTopStories(&client, &ctx, newsNumber, page, pageToken)
     } else if isUserMode {
         GetUserInfo(&client, &ctx, userName)
+    } else if isCommentsMode {
+        GetCommentTree(&client, &ctx, commentsItemId, commentsDepth, commentsChildren)
+    } else if selectedStoryList != nil {
+        GetStories(&client, &ctx, *selectedStoryList, newsNumber, page, pageToken)
+    } else if *isCacheStatsMode {
+        GetCacheStats(&adminClient, &ctx)
+    } else if isInvalidateMode {
+        Invalidate(&adminClient, &ctx, *invalidatedUserName, *invalidatedItemId)
+    } else if *isPurgeMode {
+        PurgeCache(&adminClient, &ctx, cacheName)
+    } else if *isCacheKeysMode {
+        ListCacheKeys(&adminClient, &ctx, cacheName, newsNumber)
     } else {
 		fmt.Print("Client could not choose any mode to fetch information from HackerNews")
 		flag.PrintDefaults()
@@ -21,10 +21,128 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type FrontPageEventKind int32
+
+const (
+	FrontPageEventKind_STORY_ENTERED FrontPageEventKind = 0
+	FrontPageEventKind_STORY_LEFT    FrontPageEventKind = 1
+	FrontPageEventKind_STORY_MOVED   FrontPageEventKind = 2
+	FrontPageEventKind_SCORE_CHANGED FrontPageEventKind = 3
+)
+
+// Enum value maps for FrontPageEventKind.
+var (
+	FrontPageEventKind_name = map[int32]string{
+		0: "STORY_ENTERED",
+		1: "STORY_LEFT",
+		2: "STORY_MOVED",
+		3: "SCORE_CHANGED",
+	}
+	FrontPageEventKind_value = map[string]int32{
+		"STORY_ENTERED": 0,
+		"STORY_LEFT":    1,
+		"STORY_MOVED":   2,
+		"SCORE_CHANGED": 3,
+	}
+)
+
+func (x FrontPageEventKind) Enum() *FrontPageEventKind {
+	p := new(FrontPageEventKind)
+	*p = x
+	return p
+}
+
+func (x FrontPageEventKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FrontPageEventKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_grpc_news_proto_enumTypes[0].Descriptor()
+}
+
+func (FrontPageEventKind) Type() protoreflect.EnumType {
+	return &file_grpc_news_proto_enumTypes[0]
+}
+
+func (x FrontPageEventKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FrontPageEventKind.Descriptor instead.
+func (FrontPageEventKind) EnumDescriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{0}
+}
+
+type StoryListKind int32
+
+const (
+	StoryListKind_TOP_STORIES  StoryListKind = 0
+	StoryListKind_NEW_STORIES  StoryListKind = 1
+	StoryListKind_BEST_STORIES StoryListKind = 2
+	StoryListKind_ASK_STORIES  StoryListKind = 3
+	StoryListKind_SHOW_STORIES StoryListKind = 4
+	StoryListKind_JOB_STORIES  StoryListKind = 5
+)
+
+// Enum value maps for StoryListKind.
+var (
+	StoryListKind_name = map[int32]string{
+		0: "TOP_STORIES",
+		1: "NEW_STORIES",
+		2: "BEST_STORIES",
+		3: "ASK_STORIES",
+		4: "SHOW_STORIES",
+		5: "JOB_STORIES",
+	}
+	StoryListKind_value = map[string]int32{
+		"TOP_STORIES":  0,
+		"NEW_STORIES":  1,
+		"BEST_STORIES": 2,
+		"ASK_STORIES":  3,
+		"SHOW_STORIES": 4,
+		"JOB_STORIES":  5,
+	}
+)
+
+func (x StoryListKind) Enum() *StoryListKind {
+	p := new(StoryListKind)
+	*p = x
+	return p
+}
+
+func (x StoryListKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StoryListKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_grpc_news_proto_enumTypes[1].Descriptor()
+}
+
+func (StoryListKind) Type() protoreflect.EnumType {
+	return &file_grpc_news_proto_enumTypes[1]
+}
+
+func (x StoryListKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StoryListKind.Descriptor instead.
+func (StoryListKind) EnumDescriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{1}
+}
+
 type Story struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
 	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Id            int64                  `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	By            string                 `protobuf:"bytes,5,opt,name=by,proto3" json:"by,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Score         int64                  `protobuf:"varint,7,opt,name=score,proto3" json:"score,omitempty"`
+	Descendants   int64                  `protobuf:"varint,8,opt,name=descendants,proto3" json:"descendants,omitempty"`
+	Text          string                 `protobuf:"bytes,9,opt,name=text,proto3" json:"text,omitempty"`
+	Kids          []int64                `protobuf:"varint,10,rep,packed,name=kids,proto3" json:"kids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,27 +191,96 @@ func (x *Story) GetUrl() string {
 	return ""
 }
 
-type TopStories struct {
+func (x *Story) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Story) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Story) GetBy() string {
+	if x != nil {
+		return x.By
+	}
+	return ""
+}
+
+func (x *Story) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Story) GetScore() int64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Story) GetDescendants() int64 {
+	if x != nil {
+		return x.Descendants
+	}
+	return 0
+}
+
+func (x *Story) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Story) GetKids() []int64 {
+	if x != nil {
+		return x.Kids
+	}
+	return nil
+}
+
+type Item struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Stories       []*Story               `protobuf:"bytes,1,rep,name=stories,proto3" json:"stories,omitempty"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	By            string                 `protobuf:"bytes,3,opt,name=by,proto3" json:"by,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Title         string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	Url           string                 `protobuf:"bytes,6,opt,name=url,proto3" json:"url,omitempty"`
+	Text          string                 `protobuf:"bytes,7,opt,name=text,proto3" json:"text,omitempty"`
+	Score         int64                  `protobuf:"varint,8,opt,name=score,proto3" json:"score,omitempty"`
+	Descendants   int64                  `protobuf:"varint,9,opt,name=descendants,proto3" json:"descendants,omitempty"`
+	Parent        int64                  `protobuf:"varint,10,opt,name=parent,proto3" json:"parent,omitempty"`
+	Kids          []int64                `protobuf:"varint,11,rep,packed,name=kids,proto3" json:"kids,omitempty"`
+	Parts         []int64                `protobuf:"varint,12,rep,packed,name=parts,proto3" json:"parts,omitempty"`
+	Dead          bool                   `protobuf:"varint,13,opt,name=dead,proto3" json:"dead,omitempty"`
+	Deleted       bool                   `protobuf:"varint,14,opt,name=deleted,proto3" json:"deleted,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TopStories) Reset() {
-	*x = TopStories{}
+func (x *Item) Reset() {
+	*x = Item{}
 	mi := &file_grpc_news_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TopStories) String() string {
+func (x *Item) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TopStories) ProtoMessage() {}
+func (*Item) ProtoMessage() {}
 
-func (x *TopStories) ProtoReflect() protoreflect.Message {
+func (x *Item) ProtoReflect() protoreflect.Message {
 	mi := &file_grpc_news_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -105,42 +292,140 @@ func (x *TopStories) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TopStories.ProtoReflect.Descriptor instead.
-func (*TopStories) Descriptor() ([]byte, []int) {
+// Deprecated: Use Item.ProtoReflect.Descriptor instead.
+func (*Item) Descriptor() ([]byte, []int) {
 	return file_grpc_news_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *TopStories) GetStories() []*Story {
+func (x *Item) GetId() int64 {
 	if x != nil {
-		return x.Stories
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Item) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Item) GetBy() string {
+	if x != nil {
+		return x.By
+	}
+	return ""
+}
+
+func (x *Item) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Item) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Item) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Item) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Item) GetScore() int64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Item) GetDescendants() int64 {
+	if x != nil {
+		return x.Descendants
+	}
+	return 0
+}
+
+func (x *Item) GetParent() int64 {
+	if x != nil {
+		return x.Parent
+	}
+	return 0
+}
+
+func (x *Item) GetKids() []int64 {
+	if x != nil {
+		return x.Kids
 	}
 	return nil
 }
 
-type User struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Nickname      string                 `protobuf:"bytes,1,opt,name=nickname,proto3" json:"nickname,omitempty"`
-	Karma         uint64                 `protobuf:"varint,2,opt,name=karma,proto3" json:"karma,omitempty"`
-	About         string                 `protobuf:"bytes,3,opt,name=about,proto3" json:"about,omitempty"`
-	JoinedAt      int64                  `protobuf:"varint,4,opt,name=joined_at,json=joinedAt,proto3" json:"joined_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Item) GetParts() []int64 {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
 }
 
-func (x *User) Reset() {
-	*x = User{}
+func (x *Item) GetDead() bool {
+	if x != nil {
+		return x.Dead
+	}
+	return false
+}
+
+func (x *Item) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type TopStories struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Stories []*Story               `protobuf:"bytes,1,rep,name=stories,proto3" json:"stories,omitempty"`
+	// Only set when stories are paginated and some are left
+	NextPageToken string `protobuf:"bytes,2,opt,name=nextPageToken,proto3" json:"nextPageToken,omitempty"`
+	// Number of stories in the whole list, which can be fewer than requested
+	AvailableStoryCount uint32 `protobuf:"varint,3,opt,name=availableStoryCount,proto3" json:"availableStoryCount,omitempty"`
+	// Set when HackerNews API could not be reached, the last list and stories fetched being served instead
+	Stale bool `protobuf:"varint,4,opt,name=stale,proto3" json:"stale,omitempty"`
+	// Time since the oldest stale data served was fetched, only set when stale
+	StaleAgeSeconds uint32 `protobuf:"varint,5,opt,name=staleAgeSeconds,proto3" json:"staleAgeSeconds,omitempty"`
+	// Unix time in seconds when the ranking of stories was fetched from HackerNews API, the proxy caching it briefly
+	RankingFetchedAt int64 `protobuf:"varint,6,opt,name=rankingFetchedAt,proto3" json:"rankingFetchedAt,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *TopStories) Reset() {
+	*x = TopStories{}
 	mi := &file_grpc_news_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *User) String() string {
+func (x *TopStories) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*User) ProtoMessage() {}
+func (*TopStories) ProtoMessage() {}
 
-func (x *User) ProtoReflect() protoreflect.Message {
+func (x *TopStories) ProtoReflect() protoreflect.Message {
 	mi := &file_grpc_news_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -152,60 +437,76 @@ func (x *User) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use User.ProtoReflect.Descriptor instead.
-func (*User) Descriptor() ([]byte, []int) {
+// Deprecated: Use TopStories.ProtoReflect.Descriptor instead.
+func (*TopStories) Descriptor() ([]byte, []int) {
 	return file_grpc_news_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *User) GetNickname() string {
+func (x *TopStories) GetStories() []*Story {
 	if x != nil {
-		return x.Nickname
+		return x.Stories
+	}
+	return nil
+}
+
+func (x *TopStories) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
 	}
 	return ""
 }
 
-func (x *User) GetKarma() uint64 {
+func (x *TopStories) GetAvailableStoryCount() uint32 {
 	if x != nil {
-		return x.Karma
+		return x.AvailableStoryCount
 	}
 	return 0
 }
 
-func (x *User) GetAbout() string {
+func (x *TopStories) GetStale() bool {
 	if x != nil {
-		return x.About
+		return x.Stale
 	}
-	return ""
+	return false
 }
 
-func (x *User) GetJoinedAt() int64 {
+func (x *TopStories) GetStaleAgeSeconds() uint32 {
 	if x != nil {
-		return x.JoinedAt
+		return x.StaleAgeSeconds
 	}
 	return 0
 }
 
-type TopStoriesRequest struct {
+func (x *TopStories) GetRankingFetchedAt() int64 {
+	if x != nil {
+		return x.RankingFetchedAt
+	}
+	return 0
+}
+
+// Story along with its rank in the list, starting from 1
+type RankedStory struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	StoryNumber   uint32                 `protobuf:"varint,1,opt,name=storyNumber,proto3" json:"storyNumber,omitempty"`
+	Rank          uint32                 `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"`
+	Story         *Story                 `protobuf:"bytes,2,opt,name=story,proto3" json:"story,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TopStoriesRequest) Reset() {
-	*x = TopStoriesRequest{}
+func (x *RankedStory) Reset() {
+	*x = RankedStory{}
 	mi := &file_grpc_news_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TopStoriesRequest) String() string {
+func (x *RankedStory) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TopStoriesRequest) ProtoMessage() {}
+func (*RankedStory) ProtoMessage() {}
 
-func (x *TopStoriesRequest) ProtoReflect() protoreflect.Message {
+func (x *RankedStory) ProtoReflect() protoreflect.Message {
 	mi := &file_grpc_news_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -217,39 +518,45 @@ func (x *TopStoriesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TopStoriesRequest.ProtoReflect.Descriptor instead.
-func (*TopStoriesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use RankedStory.ProtoReflect.Descriptor instead.
+func (*RankedStory) Descriptor() ([]byte, []int) {
 	return file_grpc_news_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *TopStoriesRequest) GetStoryNumber() uint32 {
+func (x *RankedStory) GetRank() uint32 {
 	if x != nil {
-		return x.StoryNumber
+		return x.Rank
 	}
 	return 0
 }
 
-type UserInfoRequest struct {
+func (x *RankedStory) GetStory() *Story {
+	if x != nil {
+		return x.Story
+	}
+	return nil
+}
+
+type WatchTopStoriesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UserInfoRequest) Reset() {
-	*x = UserInfoRequest{}
+func (x *WatchTopStoriesRequest) Reset() {
+	*x = WatchTopStoriesRequest{}
 	mi := &file_grpc_news_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UserInfoRequest) String() string {
+func (x *WatchTopStoriesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UserInfoRequest) ProtoMessage() {}
+func (*WatchTopStoriesRequest) ProtoMessage() {}
 
-func (x *UserInfoRequest) ProtoReflect() protoreflect.Message {
+func (x *WatchTopStoriesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_grpc_news_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -261,42 +568,1145 @@ func (x *UserInfoRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UserInfoRequest.ProtoReflect.Descriptor instead.
-func (*UserInfoRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use WatchTopStoriesRequest.ProtoReflect.Descriptor instead.
+func (*WatchTopStoriesRequest) Descriptor() ([]byte, []int) {
 	return file_grpc_news_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *UserInfoRequest) GetName() string {
+// Change observed on the front page. Ranks start from 1, and rank is 0 for stories that left the front page
+type FrontPageEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          FrontPageEventKind     `protobuf:"varint,1,opt,name=kind,proto3,enum=hackernews.FrontPageEventKind" json:"kind,omitempty"`
+	Story         *Story                 `protobuf:"bytes,2,opt,name=story,proto3" json:"story,omitempty"`
+	Rank          uint32                 `protobuf:"varint,3,opt,name=rank,proto3" json:"rank,omitempty"`
+	PreviousRank  uint32                 `protobuf:"varint,4,opt,name=previousRank,proto3" json:"previousRank,omitempty"`
+	PreviousScore int64                  `protobuf:"varint,5,opt,name=previousScore,proto3" json:"previousScore,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FrontPageEvent) Reset() {
+	*x = FrontPageEvent{}
+	mi := &file_grpc_news_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FrontPageEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FrontPageEvent) ProtoMessage() {}
+
+func (x *FrontPageEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[5]
 	if x != nil {
-		return x.Name
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-var File_grpc_news_proto protoreflect.FileDescriptor
+// Deprecated: Use FrontPageEvent.ProtoReflect.Descriptor instead.
+func (*FrontPageEvent) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{5}
+}
 
-const file_grpc_news_proto_rawDesc = "" +
-	"\n" +
-	"\x0fgrpc_news.proto\x12\n" +
-	"hackernews\"/\n" +
-	"\x05Story\x12\x14\n" +
+func (x *FrontPageEvent) GetKind() FrontPageEventKind {
+	if x != nil {
+		return x.Kind
+	}
+	return FrontPageEventKind_STORY_ENTERED
+}
+
+func (x *FrontPageEvent) GetStory() *Story {
+	if x != nil {
+		return x.Story
+	}
+	return nil
+}
+
+func (x *FrontPageEvent) GetRank() uint32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *FrontPageEvent) GetPreviousRank() uint32 {
+	if x != nil {
+		return x.PreviousRank
+	}
+	return 0
+}
+
+func (x *FrontPageEvent) GetPreviousScore() int64 {
+	if x != nil {
+		return x.PreviousScore
+	}
+	return 0
+}
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nickname      string                 `protobuf:"bytes,1,opt,name=nickname,proto3" json:"nickname,omitempty"`
+	Karma         uint64                 `protobuf:"varint,2,opt,name=karma,proto3" json:"karma,omitempty"`
+	About         string                 `protobuf:"bytes,3,opt,name=about,proto3" json:"about,omitempty"`
+	JoinedAt      int64                  `protobuf:"varint,4,opt,name=joined_at,json=joinedAt,proto3" json:"joined_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_grpc_news_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *User) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+func (x *User) GetKarma() uint64 {
+	if x != nil {
+		return x.Karma
+	}
+	return 0
+}
+
+func (x *User) GetAbout() string {
+	if x != nil {
+		return x.About
+	}
+	return ""
+}
+
+func (x *User) GetJoinedAt() int64 {
+	if x != nil {
+		return x.JoinedAt
+	}
+	return 0
+}
+
+// Stories are paginated as soon as pageSize is set, storyNumber is then ignored.
+// A page token fetches the page following the one that issued it, in the same ranking, and offset is then ignored
+type TopStoriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StoryNumber   uint32                 `protobuf:"varint,1,opt,name=storyNumber,proto3" json:"storyNumber,omitempty"`
+	Offset        uint32                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	PageSize      uint32                 `protobuf:"varint,3,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	PageToken     string                 `protobuf:"bytes,4,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopStoriesRequest) Reset() {
+	*x = TopStoriesRequest{}
+	mi := &file_grpc_news_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopStoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopStoriesRequest) ProtoMessage() {}
+
+func (x *TopStoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopStoriesRequest.ProtoReflect.Descriptor instead.
+func (*TopStoriesRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TopStoriesRequest) GetStoryNumber() uint32 {
+	if x != nil {
+		return x.StoryNumber
+	}
+	return 0
+}
+
+func (x *TopStoriesRequest) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *TopStoriesRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *TopStoriesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// Paginated the same way as TopStoriesRequest
+type StoriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          StoryListKind          `protobuf:"varint,1,opt,name=kind,proto3,enum=hackernews.StoryListKind" json:"kind,omitempty"`
+	StoryNumber   uint32                 `protobuf:"varint,2,opt,name=storyNumber,proto3" json:"storyNumber,omitempty"`
+	Offset        uint32                 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	PageSize      uint32                 `protobuf:"varint,4,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	PageToken     string                 `protobuf:"bytes,5,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoriesRequest) Reset() {
+	*x = StoriesRequest{}
+	mi := &file_grpc_news_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoriesRequest) ProtoMessage() {}
+
+func (x *StoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoriesRequest.ProtoReflect.Descriptor instead.
+func (*StoriesRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StoriesRequest) GetKind() StoryListKind {
+	if x != nil {
+		return x.Kind
+	}
+	return StoryListKind_TOP_STORIES
+}
+
+func (x *StoriesRequest) GetStoryNumber() uint32 {
+	if x != nil {
+		return x.StoryNumber
+	}
+	return 0
+}
+
+func (x *StoriesRequest) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *StoriesRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *StoriesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ItemRequest) Reset() {
+	*x = ItemRequest{}
+	mi := &file_grpc_news_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemRequest) ProtoMessage() {}
+
+func (x *ItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemRequest.ProtoReflect.Descriptor instead.
+func (*ItemRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CommentTreeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	MaxDepth      uint32                 `protobuf:"varint,2,opt,name=maxDepth,proto3" json:"maxDepth,omitempty"`
+	MaxChildren   uint32                 `protobuf:"varint,3,opt,name=maxChildren,proto3" json:"maxChildren,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommentTreeRequest) Reset() {
+	*x = CommentTreeRequest{}
+	mi := &file_grpc_news_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommentTreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommentTreeRequest) ProtoMessage() {}
+
+func (x *CommentTreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommentTreeRequest.ProtoReflect.Descriptor instead.
+func (*CommentTreeRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CommentTreeRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *CommentTreeRequest) GetMaxDepth() uint32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *CommentTreeRequest) GetMaxChildren() uint32 {
+	if x != nil {
+		return x.MaxChildren
+	}
+	return 0
+}
+
+type CommentTree struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Item          *Item                  `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	Children      []*CommentTree         `protobuf:"bytes,2,rep,name=children,proto3" json:"children,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommentTree) Reset() {
+	*x = CommentTree{}
+	mi := &file_grpc_news_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommentTree) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommentTree) ProtoMessage() {}
+
+func (x *CommentTree) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommentTree.ProtoReflect.Descriptor instead.
+func (*CommentTree) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CommentTree) GetItem() *Item {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+func (x *CommentTree) GetChildren() []*CommentTree {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+type UserInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserInfoRequest) Reset() {
+	*x = UserInfoRequest{}
+	mi := &file_grpc_news_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserInfoRequest) ProtoMessage() {}
+
+func (x *UserInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserInfoRequest.ProtoReflect.Descriptor instead.
+func (*UserInfoRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UserInfoRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type CacheStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the cache to get statistics of, every cache being returned when empty
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheStatsRequest) Reset() {
+	*x = CacheStatsRequest{}
+	mi := &file_grpc_news_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStatsRequest) ProtoMessage() {}
+
+func (x *CacheStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStatsRequest.ProtoReflect.Descriptor instead.
+func (*CacheStatsRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CacheStatsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Usage of a cache since the server started
+type CacheStats struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Name   string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Hits   uint64                 `protobuf:"varint,2,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses uint64                 `protobuf:"varint,3,opt,name=misses,proto3" json:"misses,omitempty"`
+	// Entries removed to make room for new ones
+	Evictions uint64 `protobuf:"varint,4,opt,name=evictions,proto3" json:"evictions,omitempty"`
+	// Entries removed because their time to live ellapsed
+	Expirations uint64 `protobuf:"varint,5,opt,name=expirations,proto3" json:"expirations,omitempty"`
+	Size        uint64 `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	// Age of the earliest added entry still in cache, 0 when cache is empty
+	OldestEntryAgeSeconds int64 `protobuf:"varint,7,opt,name=oldestEntryAgeSeconds,proto3" json:"oldestEntryAgeSeconds,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *CacheStats) Reset() {
+	*x = CacheStats{}
+	mi := &file_grpc_news_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStats) ProtoMessage() {}
+
+func (x *CacheStats) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStats.ProtoReflect.Descriptor instead.
+func (*CacheStats) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CacheStats) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CacheStats) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *CacheStats) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *CacheStats) GetEvictions() uint64 {
+	if x != nil {
+		return x.Evictions
+	}
+	return 0
+}
+
+func (x *CacheStats) GetExpirations() uint64 {
+	if x != nil {
+		return x.Expirations
+	}
+	return 0
+}
+
+func (x *CacheStats) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CacheStats) GetOldestEntryAgeSeconds() int64 {
+	if x != nil {
+		return x.OldestEntryAgeSeconds
+	}
+	return 0
+}
+
+type CacheStatsList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Caches        []*CacheStats          `protobuf:"bytes,1,rep,name=caches,proto3" json:"caches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheStatsList) Reset() {
+	*x = CacheStatsList{}
+	mi := &file_grpc_news_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheStatsList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStatsList) ProtoMessage() {}
+
+func (x *CacheStatsList) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStatsList.ProtoReflect.Descriptor instead.
+func (*CacheStatsList) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CacheStatsList) GetCaches() []*CacheStats {
+	if x != nil {
+		return x.Caches
+	}
+	return nil
+}
+
+type InvalidateUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvalidateUserRequest) Reset() {
+	*x = InvalidateUserRequest{}
+	mi := &file_grpc_news_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvalidateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvalidateUserRequest) ProtoMessage() {}
+
+func (x *InvalidateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvalidateUserRequest.ProtoReflect.Descriptor instead.
+func (*InvalidateUserRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *InvalidateUserRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type InvalidateItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvalidateItemRequest) Reset() {
+	*x = InvalidateItemRequest{}
+	mi := &file_grpc_news_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvalidateItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvalidateItemRequest) ProtoMessage() {}
+
+func (x *InvalidateItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvalidateItemRequest.ProtoReflect.Descriptor instead.
+func (*InvalidateItemRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *InvalidateItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type InvalidationResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvalidationResult) Reset() {
+	*x = InvalidationResult{}
+	mi := &file_grpc_news_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvalidationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvalidationResult) ProtoMessage() {}
+
+func (x *InvalidationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvalidationResult.ProtoReflect.Descriptor instead.
+func (*InvalidationResult) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{18}
+}
+
+type PurgeCacheRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the cache to purge, every cache being purged when empty
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeCacheRequest) Reset() {
+	*x = PurgeCacheRequest{}
+	mi := &file_grpc_news_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeCacheRequest) ProtoMessage() {}
+
+func (x *PurgeCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeCacheRequest.ProtoReflect.Descriptor instead.
+func (*PurgeCacheRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PurgeCacheRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type PurgeCacheResult struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PurgedEntryCount uint64                 `protobuf:"varint,1,opt,name=purgedEntryCount,proto3" json:"purgedEntryCount,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PurgeCacheResult) Reset() {
+	*x = PurgeCacheResult{}
+	mi := &file_grpc_news_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeCacheResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeCacheResult) ProtoMessage() {}
+
+func (x *PurgeCacheResult) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeCacheResult.ProtoReflect.Descriptor instead.
+func (*PurgeCacheResult) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PurgeCacheResult) GetPurgedEntryCount() uint64 {
+	if x != nil {
+		return x.PurgedEntryCount
+	}
+	return 0
+}
+
+type ListCacheKeysRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Max number of keys to list, every key being listed when 0
+	MaxKeys       uint32 `protobuf:"varint,2,opt,name=maxKeys,proto3" json:"maxKeys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCacheKeysRequest) Reset() {
+	*x = ListCacheKeysRequest{}
+	mi := &file_grpc_news_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCacheKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCacheKeysRequest) ProtoMessage() {}
+
+func (x *ListCacheKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCacheKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListCacheKeysRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListCacheKeysRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ListCacheKeysRequest) GetMaxKeys() uint32 {
+	if x != nil {
+		return x.MaxKeys
+	}
+	return 0
+}
+
+// Keys of a cache, which can be fewer than the keys it holds when they are limited by the request
+type CacheKeys struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	TotalKeyCount uint64                 `protobuf:"varint,2,opt,name=totalKeyCount,proto3" json:"totalKeyCount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheKeys) Reset() {
+	*x = CacheKeys{}
+	mi := &file_grpc_news_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheKeys) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheKeys) ProtoMessage() {}
+
+func (x *CacheKeys) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_news_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheKeys.ProtoReflect.Descriptor instead.
+func (*CacheKeys) Descriptor() ([]byte, []int) {
+	return file_grpc_news_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CacheKeys) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *CacheKeys) GetTotalKeyCount() uint64 {
+	if x != nil {
+		return x.TotalKeyCount
+	}
+	return 0
+}
+
+var File_grpc_news_proto protoreflect.FileDescriptor
+
+const file_grpc_news_proto_rawDesc = "" +
+	"\n" +
+	"\x0fgrpc_news.proto\x12\n" +
+	"hackernews\"\xe2\x01\n" +
+	"\x05Story\x12\x14\n" +
 	"\x05title\x18\x01 \x01(\tR\x05title\x12\x10\n" +
-	"\x03url\x18\x02 \x01(\tR\x03url\"9\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02by\x18\x05 \x01(\tR\x02by\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x14\n" +
+	"\x05score\x18\a \x01(\x03R\x05score\x12 \n" +
+	"\vdescendants\x18\b \x01(\x03R\vdescendants\x12\x12\n" +
+	"\x04text\x18\t \x01(\tR\x04text\x12\x12\n" +
+	"\x04kids\x18\n" +
+	" \x03(\x03R\x04kids\"\xbd\x02\n" +
+	"\x04Item\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02by\x18\x03 \x01(\tR\x02by\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\x03R\tcreatedAt\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\x12\x10\n" +
+	"\x03url\x18\x06 \x01(\tR\x03url\x12\x12\n" +
+	"\x04text\x18\a \x01(\tR\x04text\x12\x14\n" +
+	"\x05score\x18\b \x01(\x03R\x05score\x12 \n" +
+	"\vdescendants\x18\t \x01(\x03R\vdescendants\x12\x16\n" +
+	"\x06parent\x18\n" +
+	" \x01(\x03R\x06parent\x12\x12\n" +
+	"\x04kids\x18\v \x03(\x03R\x04kids\x12\x14\n" +
+	"\x05parts\x18\f \x03(\x03R\x05parts\x12\x12\n" +
+	"\x04dead\x18\r \x01(\bR\x04dead\x12\x18\n" +
+	"\adeleted\x18\x0e \x01(\bR\adeleted\"\xfd\x01\n" +
 	"\n" +
 	"TopStories\x12+\n" +
-	"\astories\x18\x01 \x03(\v2\x11.hackernews.StoryR\astories\"k\n" +
+	"\astories\x18\x01 \x03(\v2\x11.hackernews.StoryR\astories\x12$\n" +
+	"\rnextPageToken\x18\x02 \x01(\tR\rnextPageToken\x120\n" +
+	"\x13availableStoryCount\x18\x03 \x01(\rR\x13availableStoryCount\x12\x14\n" +
+	"\x05stale\x18\x04 \x01(\bR\x05stale\x12(\n" +
+	"\x0fstaleAgeSeconds\x18\x05 \x01(\rR\x0fstaleAgeSeconds\x12*\n" +
+	"\x10rankingFetchedAt\x18\x06 \x01(\x03R\x10rankingFetchedAt\"J\n" +
+	"\vRankedStory\x12\x12\n" +
+	"\x04rank\x18\x01 \x01(\rR\x04rank\x12'\n" +
+	"\x05story\x18\x02 \x01(\v2\x11.hackernews.StoryR\x05story\"\x18\n" +
+	"\x16WatchTopStoriesRequest\"\xcb\x01\n" +
+	"\x0eFrontPageEvent\x122\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1e.hackernews.FrontPageEventKindR\x04kind\x12'\n" +
+	"\x05story\x18\x02 \x01(\v2\x11.hackernews.StoryR\x05story\x12\x12\n" +
+	"\x04rank\x18\x03 \x01(\rR\x04rank\x12\"\n" +
+	"\fpreviousRank\x18\x04 \x01(\rR\fpreviousRank\x12$\n" +
+	"\rpreviousScore\x18\x05 \x01(\x03R\rpreviousScore\"k\n" +
 	"\x04User\x12\x1a\n" +
 	"\bnickname\x18\x01 \x01(\tR\bnickname\x12\x14\n" +
 	"\x05karma\x18\x02 \x01(\x04R\x05karma\x12\x14\n" +
 	"\x05about\x18\x03 \x01(\tR\x05about\x12\x1b\n" +
-	"\tjoined_at\x18\x04 \x01(\x03R\bjoinedAt\"5\n" +
+	"\tjoined_at\x18\x04 \x01(\x03R\bjoinedAt\"\x87\x01\n" +
 	"\x11TopStoriesRequest\x12 \n" +
-	"\vstoryNumber\x18\x01 \x01(\rR\vstoryNumber\"%\n" +
+	"\vstoryNumber\x18\x01 \x01(\rR\vstoryNumber\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\rR\x06offset\x12\x1a\n" +
+	"\bpageSize\x18\x03 \x01(\rR\bpageSize\x12\x1c\n" +
+	"\tpageToken\x18\x04 \x01(\tR\tpageToken\"\xb3\x01\n" +
+	"\x0eStoriesRequest\x12-\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x19.hackernews.StoryListKindR\x04kind\x12 \n" +
+	"\vstoryNumber\x18\x02 \x01(\rR\vstoryNumber\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\rR\x06offset\x12\x1a\n" +
+	"\bpageSize\x18\x04 \x01(\rR\bpageSize\x12\x1c\n" +
+	"\tpageToken\x18\x05 \x01(\tR\tpageToken\"\x1d\n" +
+	"\vItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"b\n" +
+	"\x12CommentTreeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bmaxDepth\x18\x02 \x01(\rR\bmaxDepth\x12 \n" +
+	"\vmaxChildren\x18\x03 \x01(\rR\vmaxChildren\"h\n" +
+	"\vCommentTree\x12$\n" +
+	"\x04item\x18\x01 \x01(\v2\x10.hackernews.ItemR\x04item\x123\n" +
+	"\bchildren\x18\x02 \x03(\v2\x17.hackernews.CommentTreeR\bchildren\"%\n" +
 	"\x0fUserInfoRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name2\x8f\x01\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"'\n" +
+	"\x11CacheStatsRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\xd6\x01\n" +
+	"\n" +
+	"CacheStats\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04hits\x18\x02 \x01(\x04R\x04hits\x12\x16\n" +
+	"\x06misses\x18\x03 \x01(\x04R\x06misses\x12\x1c\n" +
+	"\tevictions\x18\x04 \x01(\x04R\tevictions\x12 \n" +
+	"\vexpirations\x18\x05 \x01(\x04R\vexpirations\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x04R\x04size\x124\n" +
+	"\x15oldestEntryAgeSeconds\x18\a \x01(\x03R\x15oldestEntryAgeSeconds\"@\n" +
+	"\x0eCacheStatsList\x12.\n" +
+	"\x06caches\x18\x01 \x03(\v2\x16.hackernews.CacheStatsR\x06caches\"+\n" +
+	"\x15InvalidateUserRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"'\n" +
+	"\x15InvalidateItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x14\n" +
+	"\x12InvalidationResult\"'\n" +
+	"\x11PurgeCacheRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\">\n" +
+	"\x10PurgeCacheResult\x12*\n" +
+	"\x10purgedEntryCount\x18\x01 \x01(\x04R\x10purgedEntryCount\"D\n" +
+	"\x14ListCacheKeysRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\amaxKeys\x18\x02 \x01(\rR\amaxKeys\"E\n" +
+	"\tCacheKeys\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\x12$\n" +
+	"\rtotalKeyCount\x18\x02 \x01(\x04R\rtotalKeyCount*[\n" +
+	"\x12FrontPageEventKind\x12\x11\n" +
+	"\rSTORY_ENTERED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"STORY_LEFT\x10\x01\x12\x0f\n" +
+	"\vSTORY_MOVED\x10\x02\x12\x11\n" +
+	"\rSCORE_CHANGED\x10\x03*w\n" +
+	"\rStoryListKind\x12\x0f\n" +
+	"\vTOP_STORIES\x10\x00\x12\x0f\n" +
+	"\vNEW_STORIES\x10\x01\x12\x10\n" +
+	"\fBEST_STORIES\x10\x02\x12\x0f\n" +
+	"\vASK_STORIES\x10\x03\x12\x10\n" +
+	"\fSHOW_STORIES\x10\x04\x12\x0f\n" +
+	"\vJOB_STORIES\x10\x052\xff\x03\n" +
 	"\tHnService\x12H\n" +
-	"\rGetTopStories\x12\x1d.hackernews.TopStoriesRequest\x1a\x16.hackernews.TopStories\"\x00\x128\n" +
-	"\x05Whois\x12\x1b.hackernews.UserInfoRequest\x1a\x10.hackernews.User\"\x00B Z\x1egithub.com/lejugeti/hackernewsb\x06proto3"
+	"\rGetTopStories\x12\x1d.hackernews.TopStoriesRequest\x1a\x16.hackernews.TopStories\"\x00\x12N\n" +
+	"\x10StreamTopStories\x12\x1d.hackernews.TopStoriesRequest\x1a\x17.hackernews.RankedStory\"\x000\x01\x12U\n" +
+	"\x0fWatchTopStories\x12\".hackernews.WatchTopStoriesRequest\x1a\x1a.hackernews.FrontPageEvent\"\x000\x01\x12B\n" +
+	"\n" +
+	"GetStories\x12\x1a.hackernews.StoriesRequest\x1a\x16.hackernews.TopStories\"\x00\x126\n" +
+	"\aGetItem\x12\x17.hackernews.ItemRequest\x1a\x10.hackernews.Item\"\x00\x12K\n" +
+	"\x0eGetCommentTree\x12\x1e.hackernews.CommentTreeRequest\x1a\x17.hackernews.CommentTree\"\x00\x128\n" +
+	"\x05Whois\x12\x1b.hackernews.UserInfoRequest\x1a\x10.hackernews.User\"\x002\xa5\x03\n" +
+	"\x0eHnAdminService\x12L\n" +
+	"\rGetCacheStats\x12\x1d.hackernews.CacheStatsRequest\x1a\x1a.hackernews.CacheStatsList\"\x00\x12U\n" +
+	"\x0eInvalidateUser\x12!.hackernews.InvalidateUserRequest\x1a\x1e.hackernews.InvalidationResult\"\x00\x12U\n" +
+	"\x0eInvalidateItem\x12!.hackernews.InvalidateItemRequest\x1a\x1e.hackernews.InvalidationResult\"\x00\x12K\n" +
+	"\n" +
+	"PurgeCache\x12\x1d.hackernews.PurgeCacheRequest\x1a\x1c.hackernews.PurgeCacheResult\"\x00\x12J\n" +
+	"\rListCacheKeys\x12 .hackernews.ListCacheKeysRequest\x1a\x15.hackernews.CacheKeys\"\x00B Z\x1egithub.com/lejugeti/hackernewsb\x06proto3"
 
 var (
 	file_grpc_news_proto_rawDescOnce sync.Once
@@ -310,25 +1720,73 @@ func file_grpc_news_proto_rawDescGZIP() []byte {
 	return file_grpc_news_proto_rawDescData
 }
 
-var file_grpc_news_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_grpc_news_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_grpc_news_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
 var file_grpc_news_proto_goTypes = []any{
-	(*Story)(nil),             // 0: hackernews.Story
-	(*TopStories)(nil),        // 1: hackernews.TopStories
-	(*User)(nil),              // 2: hackernews.User
-	(*TopStoriesRequest)(nil), // 3: hackernews.TopStoriesRequest
-	(*UserInfoRequest)(nil),   // 4: hackernews.UserInfoRequest
+	(FrontPageEventKind)(0),        // 0: hackernews.FrontPageEventKind
+	(StoryListKind)(0),             // 1: hackernews.StoryListKind
+	(*Story)(nil),                  // 2: hackernews.Story
+	(*Item)(nil),                   // 3: hackernews.Item
+	(*TopStories)(nil),             // 4: hackernews.TopStories
+	(*RankedStory)(nil),            // 5: hackernews.RankedStory
+	(*WatchTopStoriesRequest)(nil), // 6: hackernews.WatchTopStoriesRequest
+	(*FrontPageEvent)(nil),         // 7: hackernews.FrontPageEvent
+	(*User)(nil),                   // 8: hackernews.User
+	(*TopStoriesRequest)(nil),      // 9: hackernews.TopStoriesRequest
+	(*StoriesRequest)(nil),         // 10: hackernews.StoriesRequest
+	(*ItemRequest)(nil),            // 11: hackernews.ItemRequest
+	(*CommentTreeRequest)(nil),     // 12: hackernews.CommentTreeRequest
+	(*CommentTree)(nil),            // 13: hackernews.CommentTree
+	(*UserInfoRequest)(nil),        // 14: hackernews.UserInfoRequest
+	(*CacheStatsRequest)(nil),      // 15: hackernews.CacheStatsRequest
+	(*CacheStats)(nil),             // 16: hackernews.CacheStats
+	(*CacheStatsList)(nil),         // 17: hackernews.CacheStatsList
+	(*InvalidateUserRequest)(nil),  // 18: hackernews.InvalidateUserRequest
+	(*InvalidateItemRequest)(nil),  // 19: hackernews.InvalidateItemRequest
+	(*InvalidationResult)(nil),     // 20: hackernews.InvalidationResult
+	(*PurgeCacheRequest)(nil),      // 21: hackernews.PurgeCacheRequest
+	(*PurgeCacheResult)(nil),       // 22: hackernews.PurgeCacheResult
+	(*ListCacheKeysRequest)(nil),   // 23: hackernews.ListCacheKeysRequest
+	(*CacheKeys)(nil),              // 24: hackernews.CacheKeys
 }
 var file_grpc_news_proto_depIdxs = []int32{
-	0, // 0: hackernews.TopStories.stories:type_name -> hackernews.Story
-	3, // 1: hackernews.HnService.GetTopStories:input_type -> hackernews.TopStoriesRequest
-	4, // 2: hackernews.HnService.Whois:input_type -> hackernews.UserInfoRequest
-	1, // 3: hackernews.HnService.GetTopStories:output_type -> hackernews.TopStories
-	2, // 4: hackernews.HnService.Whois:output_type -> hackernews.User
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	2,  // 0: hackernews.TopStories.stories:type_name -> hackernews.Story
+	2,  // 1: hackernews.RankedStory.story:type_name -> hackernews.Story
+	0,  // 2: hackernews.FrontPageEvent.kind:type_name -> hackernews.FrontPageEventKind
+	2,  // 3: hackernews.FrontPageEvent.story:type_name -> hackernews.Story
+	1,  // 4: hackernews.StoriesRequest.kind:type_name -> hackernews.StoryListKind
+	3,  // 5: hackernews.CommentTree.item:type_name -> hackernews.Item
+	13, // 6: hackernews.CommentTree.children:type_name -> hackernews.CommentTree
+	16, // 7: hackernews.CacheStatsList.caches:type_name -> hackernews.CacheStats
+	9,  // 8: hackernews.HnService.GetTopStories:input_type -> hackernews.TopStoriesRequest
+	9,  // 9: hackernews.HnService.StreamTopStories:input_type -> hackernews.TopStoriesRequest
+	6,  // 10: hackernews.HnService.WatchTopStories:input_type -> hackernews.WatchTopStoriesRequest
+	10, // 11: hackernews.HnService.GetStories:input_type -> hackernews.StoriesRequest
+	11, // 12: hackernews.HnService.GetItem:input_type -> hackernews.ItemRequest
+	12, // 13: hackernews.HnService.GetCommentTree:input_type -> hackernews.CommentTreeRequest
+	14, // 14: hackernews.HnService.Whois:input_type -> hackernews.UserInfoRequest
+	15, // 15: hackernews.HnAdminService.GetCacheStats:input_type -> hackernews.CacheStatsRequest
+	18, // 16: hackernews.HnAdminService.InvalidateUser:input_type -> hackernews.InvalidateUserRequest
+	19, // 17: hackernews.HnAdminService.InvalidateItem:input_type -> hackernews.InvalidateItemRequest
+	21, // 18: hackernews.HnAdminService.PurgeCache:input_type -> hackernews.PurgeCacheRequest
+	23, // 19: hackernews.HnAdminService.ListCacheKeys:input_type -> hackernews.ListCacheKeysRequest
+	4,  // 20: hackernews.HnService.GetTopStories:output_type -> hackernews.TopStories
+	5,  // 21: hackernews.HnService.StreamTopStories:output_type -> hackernews.RankedStory
+	7,  // 22: hackernews.HnService.WatchTopStories:output_type -> hackernews.FrontPageEvent
+	4,  // 23: hackernews.HnService.GetStories:output_type -> hackernews.TopStories
+	3,  // 24: hackernews.HnService.GetItem:output_type -> hackernews.Item
+	13, // 25: hackernews.HnService.GetCommentTree:output_type -> hackernews.CommentTree
+	8,  // 26: hackernews.HnService.Whois:output_type -> hackernews.User
+	17, // 27: hackernews.HnAdminService.GetCacheStats:output_type -> hackernews.CacheStatsList
+	20, // 28: hackernews.HnAdminService.InvalidateUser:output_type -> hackernews.InvalidationResult
+	20, // 29: hackernews.HnAdminService.InvalidateItem:output_type -> hackernews.InvalidationResult
+	22, // 30: hackernews.HnAdminService.PurgeCache:output_type -> hackernews.PurgeCacheResult
+	24, // 31: hackernews.HnAdminService.ListCacheKeys:output_type -> hackernews.CacheKeys
+	20, // [20:32] is the sub-list for method output_type
+	8,  // [8:20] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_grpc_news_proto_init() }
@@ -341,13 +1799,14 @@ func file_grpc_news_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_grpc_news_proto_rawDesc), len(file_grpc_news_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   5,
+			NumEnums:      2,
+			NumMessages:   23,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_grpc_news_proto_goTypes,
 		DependencyIndexes: file_grpc_news_proto_depIdxs,
+		EnumInfos:         file_grpc_news_proto_enumTypes,
 		MessageInfos:      file_grpc_news_proto_msgTypes,
 	}.Build()
 	File_grpc_news_proto = out.File
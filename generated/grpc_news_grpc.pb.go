@@ -19,8 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	HnService_GetTopStories_FullMethodName = "/hackernews.HnService/GetTopStories"
-	HnService_Whois_FullMethodName         = "/hackernews.HnService/Whois"
+	HnService_GetTopStories_FullMethodName    = "/hackernews.HnService/GetTopStories"
+	HnService_StreamTopStories_FullMethodName = "/hackernews.HnService/StreamTopStories"
+	HnService_WatchTopStories_FullMethodName  = "/hackernews.HnService/WatchTopStories"
+	HnService_GetStories_FullMethodName       = "/hackernews.HnService/GetStories"
+	HnService_GetItem_FullMethodName          = "/hackernews.HnService/GetItem"
+	HnService_GetCommentTree_FullMethodName   = "/hackernews.HnService/GetCommentTree"
+	HnService_Whois_FullMethodName            = "/hackernews.HnService/Whois"
 )
 
 // HnServiceClient is the client API for HnService service.
@@ -28,6 +33,11 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type HnServiceClient interface {
 	GetTopStories(ctx context.Context, in *TopStoriesRequest, opts ...grpc.CallOption) (*TopStories, error)
+	StreamTopStories(ctx context.Context, in *TopStoriesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RankedStory], error)
+	WatchTopStories(ctx context.Context, in *WatchTopStoriesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FrontPageEvent], error)
+	GetStories(ctx context.Context, in *StoriesRequest, opts ...grpc.CallOption) (*TopStories, error)
+	GetItem(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*Item, error)
+	GetCommentTree(ctx context.Context, in *CommentTreeRequest, opts ...grpc.CallOption) (*CommentTree, error)
 	Whois(ctx context.Context, in *UserInfoRequest, opts ...grpc.CallOption) (*User, error)
 }
 
@@ -49,6 +59,74 @@ func (c *hnServiceClient) GetTopStories(ctx context.Context, in *TopStoriesReque
 	return out, nil
 }
 
+func (c *hnServiceClient) StreamTopStories(ctx context.Context, in *TopStoriesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RankedStory], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &HnService_ServiceDesc.Streams[0], HnService_StreamTopStories_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TopStoriesRequest, RankedStory]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HnService_StreamTopStoriesClient = grpc.ServerStreamingClient[RankedStory]
+
+func (c *hnServiceClient) WatchTopStories(ctx context.Context, in *WatchTopStoriesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FrontPageEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &HnService_ServiceDesc.Streams[1], HnService_WatchTopStories_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTopStoriesRequest, FrontPageEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HnService_WatchTopStoriesClient = grpc.ServerStreamingClient[FrontPageEvent]
+
+func (c *hnServiceClient) GetStories(ctx context.Context, in *StoriesRequest, opts ...grpc.CallOption) (*TopStories, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TopStories)
+	err := c.cc.Invoke(ctx, HnService_GetStories_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hnServiceClient) GetItem(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*Item, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Item)
+	err := c.cc.Invoke(ctx, HnService_GetItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hnServiceClient) GetCommentTree(ctx context.Context, in *CommentTreeRequest, opts ...grpc.CallOption) (*CommentTree, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommentTree)
+	err := c.cc.Invoke(ctx, HnService_GetCommentTree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *hnServiceClient) Whois(ctx context.Context, in *UserInfoRequest, opts ...grpc.CallOption) (*User, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(User)
@@ -64,6 +142,11 @@ func (c *hnServiceClient) Whois(ctx context.Context, in *UserInfoRequest, opts .
 // for forward compatibility.
 type HnServiceServer interface {
 	GetTopStories(context.Context, *TopStoriesRequest) (*TopStories, error)
+	StreamTopStories(*TopStoriesRequest, grpc.ServerStreamingServer[RankedStory]) error
+	WatchTopStories(*WatchTopStoriesRequest, grpc.ServerStreamingServer[FrontPageEvent]) error
+	GetStories(context.Context, *StoriesRequest) (*TopStories, error)
+	GetItem(context.Context, *ItemRequest) (*Item, error)
+	GetCommentTree(context.Context, *CommentTreeRequest) (*CommentTree, error)
 	Whois(context.Context, *UserInfoRequest) (*User, error)
 	mustEmbedUnimplementedHnServiceServer()
 }
@@ -78,6 +161,21 @@ type UnimplementedHnServiceServer struct{}
 func (UnimplementedHnServiceServer) GetTopStories(context.Context, *TopStoriesRequest) (*TopStories, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTopStories not implemented")
 }
+func (UnimplementedHnServiceServer) StreamTopStories(*TopStoriesRequest, grpc.ServerStreamingServer[RankedStory]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTopStories not implemented")
+}
+func (UnimplementedHnServiceServer) WatchTopStories(*WatchTopStoriesRequest, grpc.ServerStreamingServer[FrontPageEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTopStories not implemented")
+}
+func (UnimplementedHnServiceServer) GetStories(context.Context, *StoriesRequest) (*TopStories, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStories not implemented")
+}
+func (UnimplementedHnServiceServer) GetItem(context.Context, *ItemRequest) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItem not implemented")
+}
+func (UnimplementedHnServiceServer) GetCommentTree(context.Context, *CommentTreeRequest) (*CommentTree, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommentTree not implemented")
+}
 func (UnimplementedHnServiceServer) Whois(context.Context, *UserInfoRequest) (*User, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Whois not implemented")
 }
@@ -120,6 +218,82 @@ func _HnService_GetTopStories_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _HnService_StreamTopStories_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TopStoriesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HnServiceServer).StreamTopStories(m, &grpc.GenericServerStream[TopStoriesRequest, RankedStory]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HnService_StreamTopStoriesServer = grpc.ServerStreamingServer[RankedStory]
+
+func _HnService_WatchTopStories_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTopStoriesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HnServiceServer).WatchTopStories(m, &grpc.GenericServerStream[WatchTopStoriesRequest, FrontPageEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HnService_WatchTopStoriesServer = grpc.ServerStreamingServer[FrontPageEvent]
+
+func _HnService_GetStories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HnServiceServer).GetStories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HnService_GetStories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HnServiceServer).GetStories(ctx, req.(*StoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HnService_GetItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HnServiceServer).GetItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HnService_GetItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HnServiceServer).GetItem(ctx, req.(*ItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HnService_GetCommentTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommentTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HnServiceServer).GetCommentTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HnService_GetCommentTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HnServiceServer).GetCommentTree(ctx, req.(*CommentTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _HnService_Whois_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UserInfoRequest)
 	if err := dec(in); err != nil {
@@ -149,11 +323,292 @@ var HnService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTopStories",
 			Handler:    _HnService_GetTopStories_Handler,
 		},
+		{
+			MethodName: "GetStories",
+			Handler:    _HnService_GetStories_Handler,
+		},
+		{
+			MethodName: "GetItem",
+			Handler:    _HnService_GetItem_Handler,
+		},
+		{
+			MethodName: "GetCommentTree",
+			Handler:    _HnService_GetCommentTree_Handler,
+		},
 		{
 			MethodName: "Whois",
 			Handler:    _HnService_Whois_Handler,
 		},
 	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTopStories",
+			Handler:       _HnService_StreamTopStories_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchTopStories",
+			Handler:       _HnService_WatchTopStories_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grpc_news.proto",
+}
+
+const (
+	HnAdminService_GetCacheStats_FullMethodName  = "/hackernews.HnAdminService/GetCacheStats"
+	HnAdminService_InvalidateUser_FullMethodName = "/hackernews.HnAdminService/InvalidateUser"
+	HnAdminService_InvalidateItem_FullMethodName = "/hackernews.HnAdminService/InvalidateItem"
+	HnAdminService_PurgeCache_FullMethodName     = "/hackernews.HnAdminService/PurgeCache"
+	HnAdminService_ListCacheKeys_FullMethodName  = "/hackernews.HnAdminService/ListCacheKeys"
+)
+
+// HnAdminServiceClient is the client API for HnAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Administration of the proxy itself, rather than of HackerNews content
+type HnAdminServiceClient interface {
+	GetCacheStats(ctx context.Context, in *CacheStatsRequest, opts ...grpc.CallOption) (*CacheStatsList, error)
+	InvalidateUser(ctx context.Context, in *InvalidateUserRequest, opts ...grpc.CallOption) (*InvalidationResult, error)
+	InvalidateItem(ctx context.Context, in *InvalidateItemRequest, opts ...grpc.CallOption) (*InvalidationResult, error)
+	PurgeCache(ctx context.Context, in *PurgeCacheRequest, opts ...grpc.CallOption) (*PurgeCacheResult, error)
+	ListCacheKeys(ctx context.Context, in *ListCacheKeysRequest, opts ...grpc.CallOption) (*CacheKeys, error)
+}
+
+type hnAdminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHnAdminServiceClient(cc grpc.ClientConnInterface) HnAdminServiceClient {
+	return &hnAdminServiceClient{cc}
+}
+
+func (c *hnAdminServiceClient) GetCacheStats(ctx context.Context, in *CacheStatsRequest, opts ...grpc.CallOption) (*CacheStatsList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheStatsList)
+	err := c.cc.Invoke(ctx, HnAdminService_GetCacheStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hnAdminServiceClient) InvalidateUser(ctx context.Context, in *InvalidateUserRequest, opts ...grpc.CallOption) (*InvalidationResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InvalidationResult)
+	err := c.cc.Invoke(ctx, HnAdminService_InvalidateUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hnAdminServiceClient) InvalidateItem(ctx context.Context, in *InvalidateItemRequest, opts ...grpc.CallOption) (*InvalidationResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InvalidationResult)
+	err := c.cc.Invoke(ctx, HnAdminService_InvalidateItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hnAdminServiceClient) PurgeCache(ctx context.Context, in *PurgeCacheRequest, opts ...grpc.CallOption) (*PurgeCacheResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeCacheResult)
+	err := c.cc.Invoke(ctx, HnAdminService_PurgeCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hnAdminServiceClient) ListCacheKeys(ctx context.Context, in *ListCacheKeysRequest, opts ...grpc.CallOption) (*CacheKeys, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheKeys)
+	err := c.cc.Invoke(ctx, HnAdminService_ListCacheKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HnAdminServiceServer is the server API for HnAdminService service.
+// All implementations must embed UnimplementedHnAdminServiceServer
+// for forward compatibility.
+//
+// Administration of the proxy itself, rather than of HackerNews content
+type HnAdminServiceServer interface {
+	GetCacheStats(context.Context, *CacheStatsRequest) (*CacheStatsList, error)
+	InvalidateUser(context.Context, *InvalidateUserRequest) (*InvalidationResult, error)
+	InvalidateItem(context.Context, *InvalidateItemRequest) (*InvalidationResult, error)
+	PurgeCache(context.Context, *PurgeCacheRequest) (*PurgeCacheResult, error)
+	ListCacheKeys(context.Context, *ListCacheKeysRequest) (*CacheKeys, error)
+	mustEmbedUnimplementedHnAdminServiceServer()
+}
+
+// UnimplementedHnAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHnAdminServiceServer struct{}
+
+func (UnimplementedHnAdminServiceServer) GetCacheStats(context.Context, *CacheStatsRequest) (*CacheStatsList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCacheStats not implemented")
+}
+func (UnimplementedHnAdminServiceServer) InvalidateUser(context.Context, *InvalidateUserRequest) (*InvalidationResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InvalidateUser not implemented")
+}
+func (UnimplementedHnAdminServiceServer) InvalidateItem(context.Context, *InvalidateItemRequest) (*InvalidationResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InvalidateItem not implemented")
+}
+func (UnimplementedHnAdminServiceServer) PurgeCache(context.Context, *PurgeCacheRequest) (*PurgeCacheResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeCache not implemented")
+}
+func (UnimplementedHnAdminServiceServer) ListCacheKeys(context.Context, *ListCacheKeysRequest) (*CacheKeys, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCacheKeys not implemented")
+}
+func (UnimplementedHnAdminServiceServer) mustEmbedUnimplementedHnAdminServiceServer() {}
+func (UnimplementedHnAdminServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeHnAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HnAdminServiceServer will
+// result in compilation errors.
+type UnsafeHnAdminServiceServer interface {
+	mustEmbedUnimplementedHnAdminServiceServer()
+}
+
+func RegisterHnAdminServiceServer(s grpc.ServiceRegistrar, srv HnAdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedHnAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&HnAdminService_ServiceDesc, srv)
+}
+
+func _HnAdminService_GetCacheStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CacheStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HnAdminServiceServer).GetCacheStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HnAdminService_GetCacheStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HnAdminServiceServer).GetCacheStats(ctx, req.(*CacheStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HnAdminService_InvalidateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvalidateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HnAdminServiceServer).InvalidateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HnAdminService_InvalidateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HnAdminServiceServer).InvalidateUser(ctx, req.(*InvalidateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HnAdminService_InvalidateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvalidateItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HnAdminServiceServer).InvalidateItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HnAdminService_InvalidateItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HnAdminServiceServer).InvalidateItem(ctx, req.(*InvalidateItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HnAdminService_PurgeCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HnAdminServiceServer).PurgeCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HnAdminService_PurgeCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HnAdminServiceServer).PurgeCache(ctx, req.(*PurgeCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HnAdminService_ListCacheKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCacheKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HnAdminServiceServer).ListCacheKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HnAdminService_ListCacheKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HnAdminServiceServer).ListCacheKeys(ctx, req.(*ListCacheKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HnAdminService_ServiceDesc is the grpc.ServiceDesc for HnAdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HnAdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "hackernews.HnAdminService",
+	HandlerType: (*HnAdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCacheStats",
+			Handler:    _HnAdminService_GetCacheStats_Handler,
+		},
+		{
+			MethodName: "InvalidateUser",
+			Handler:    _HnAdminService_InvalidateUser_Handler,
+		},
+		{
+			MethodName: "InvalidateItem",
+			Handler:    _HnAdminService_InvalidateItem_Handler,
+		},
+		{
+			MethodName: "PurgeCache",
+			Handler:    _HnAdminService_PurgeCache_Handler,
+		},
+		{
+			MethodName: "ListCacheKeys",
+			Handler:    _HnAdminService_ListCacheKeys_Handler,
+		},
+	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "grpc_news.proto",
 }
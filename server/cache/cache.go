@@ -1,7 +1,49 @@
 package cache
 
+import (
+	"context"
+	"fmt"
+)
+
 type Cache[K comparable, V any] interface {
+	Inspectable
 	Add(key K, value V)
 	Get(key K) (V, bool)
+	// Same as Get, except that stale entries are refreshed in background, while their stale value is returned meanwhile
+	GetOrRevalidate(key K, refresh func() (V, error)) (V, bool)
+	// Same as GetOrRevalidate, except that missing entries are loaded and cached.
+	// Concurrent misses of a same key share a single loader call, and its error if it fails.
+	// The loader context is only cancelled once every caller waiting for it has given up, a caller whose ctx is done
+	// returning its error right away
+	GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (V, error)
+	// Same as GetOrLoad for callers which already looked key up and missed it: the load is not recorded again in stats
+	Load(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (V, error)
 	Delete(key K)
+	// Keys of entries which have not expired yet
+	Keys() []K
+	// Entries which have not expired yet, along with their timing
+	Entries() []Entry[K, V]
+	// Adds entries with the timing they had when listed, except for the expired ones. Returns the number of restored entries
+	Restore(entries []Entry[K, V]) int
+	// Releases background resources held by the cache
+	Close()
+}
+
+// Cache operations which do not depend on its keys and values types, so that caches of any kind can be inspected together
+type Inspectable interface {
+	Stats() Stats
+	// Keys of entries which have not expired yet, formatted for display
+	FormattedKeys() []string
+	// Removes every entry, and returns how many were removed
+	Clear() int
+}
+
+func formatKeys[K comparable](keys []K) []string {
+	formattedKeys := make([]string, len(keys))
+
+	for i, key := range keys {
+		formattedKeys[i] = fmt.Sprint(key)
+	}
+
+	return formattedKeys
 }
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// Coalesces concurrent loads of a same key: a single loader runs, while other callers wait for its result
+type loadGroup[K comparable, V any] struct {
+	mutex sync.Mutex
+	inFlight map[K]*load[V]
+}
+
+type load[V any] struct {
+	done chan struct{}
+	value V
+	err error
+	// callers still waiting for the load, which is cancelled once none is left
+	waitersCount int
+	cancel context.CancelFunc
+}
+
+func newLoadGroup[K comparable, V any]() *loadGroup[K, V] {
+	return &loadGroup[K, V]{inFlight: make(map[K]*load[V])}
+}
+
+// Runs loader unless a load of key is already running, in which case its result is awaited and shared.
+// The loader is given a context of its own, traced within the call which started it and bound to its deadline, but only
+// cancelled once every caller waiting for it has given up, so that a caller leaving does not fail the others.
+// A caller whose ctx is done stops waiting
+func (group *loadGroup[K, V]) do(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (V, error) {
+	if err := ctx.Err(); err != nil {
+		var zero V
+		return zero, err
+	}
+
+	group.mutex.Lock()
+
+	current, isLoading := group.inFlight[key]
+	if !isLoading {
+		loadCtx, cancel := Detach(ctx)
+		current = &load[V]{done: make(chan struct{}), cancel: cancel}
+		group.inFlight[key] = current
+
+		go group.run(loadCtx, key, current, loader)
+	}
+	current.waitersCount++
+
+	group.mutex.Unlock()
+
+	select {
+	case <-current.done:
+		return current.value, current.err
+	case <-ctx.Done():
+		group.leave(key, current)
+
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (group *loadGroup[K, V]) run(ctx context.Context, key K, current *load[V], loader func(ctx context.Context) (V, error)) {
+	defer current.cancel()
+
+	current.value, current.err = loader(ctx)
+
+	group.mutex.Lock()
+	if group.inFlight[key] == current {
+		delete(group.inFlight, key)
+	}
+	group.mutex.Unlock()
+
+	close(current.done)
+}
+
+// Stops waiting for a load, cancelling it when no caller is left waiting.
+// A cancelled load is forgotten right away, so that following callers start a new one rather than sharing its failure
+func (group *loadGroup[K, V]) leave(key K, current *load[V]) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	current.waitersCount--
+	if current.waitersCount > 0 {
+		return
+	}
+
+	current.cancel()
+	if group.inFlight[key] == current {
+		delete(group.inFlight, key)
+	}
+}
+
+// Gets key from cache, refreshing it in background if stale, or loads it through group and caches it when missing.
+// Failed loads are not cached. Background refreshes outlive the cancellation of ctx, but not its deadline
+func getOrLoad[K comparable, V any](ctx context.Context, cache loadingCache[K, V], group *loadGroup[K, V], key K, loader func(ctx context.Context) (V, error)) (V, error) {
+	refresh := func() (V, error) {
+		refreshCtx, cancel := Detach(ctx)
+		defer cancel()
+
+		return loader(refreshCtx)
+	}
+	if value, isCached := cache.GetOrRevalidate(key, refresh); isCached {
+		return value, nil
+	}
+
+	return loadMissing(ctx, cache, group, key, loader)
+}
+
+// Loads key through group and caches it, unless a load which completed in the meantime already cached it.
+// Not recorded in cache stats, the caller having recorded its own lookup
+func loadMissing[K comparable, V any](ctx context.Context, cache loadingCache[K, V], group *loadGroup[K, V], key K, loader func(ctx context.Context) (V, error)) (V, error) {
+	return group.do(ctx, key, func(loadCtx context.Context) (V, error) {
+		// a load which completed in the meantime may have already cached it
+		if value, isCached := cache.peek(key); isCached {
+			return value, nil
+		}
+
+		value, err := loader(loadCtx)
+		if err == nil {
+			cache.Add(key, value)
+		}
+
+		return value, err
+	})
+}
+
+// Cache operations getOrLoad relies on
+type loadingCache[K comparable, V any] interface {
+	Add(key K, value V)
+	GetOrRevalidate(key K, refresh func() (V, error)) (V, bool)
+	// Same as Get, but not recorded in cache stats
+	peek(key K) (V, bool)
+}
+
+// Context of work done on behalf of ctx which outlives its cancellation, but not its deadline: keeping the deadline
+// lets the work give up in time, such as requests to HackerNews API not being retried past it
+func Detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+		return context.WithDeadline(detached, deadline)
+	}
+
+	return context.WithCancel(detached)
+}
@@ -0,0 +1,259 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadShouldCallLoaderOnceForConcurrentMisses(t *testing.T) {
+	caches := map[string]Cache[int, int]{
+		"time to live cache": NewTimeToLiveCache[int, int](time.Minute),
+		"lru time to live cache": NewLruTimeToLiveCache[int, int](time.Minute, 10),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			defer cache.Close()
+			const key int = 1
+			const callersCount int = 20
+
+			var loaderCalls atomic.Int32
+			release := make(chan struct{})
+
+			loader := func(ctx context.Context) (int, error) {
+				loaderCalls.Add(1)
+				<-release
+				return 42, nil
+			}
+
+			// WHEN
+			var callers sync.WaitGroup
+			values := make([]int, callersCount)
+
+			for i := range callersCount {
+				callers.Add(1)
+				go func() {
+					defer callers.Done()
+					values[i], _ = cache.GetOrLoad(context.Background(), key, loader)
+				}()
+			}
+
+			time.Sleep(time.Millisecond * 20)
+			close(release)
+			callers.Wait()
+
+			// THEN
+			if loaderCalls.Load() != 1 {
+				t.Errorf("expected a single loader call but got %d", loaderCalls.Load())
+			}
+
+			for _, value := range values {
+				if value != 42 {
+					t.Errorf("expected every caller to get loaded value '42' but got '%d'", value)
+				}
+			}
+
+			if cached, isCached := cache.Get(key); !isCached || cached != 42 {
+				t.Error("loaded value should have been added to cache")
+			}
+		})
+	}
+}
+
+func TestGetOrLoadShouldNotCallLoaderIfCached(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute)
+	defer cache.Close()
+	const key int = 1
+
+	cache.Add(key, 1)
+
+	// WHEN
+	actual, err := cache.GetOrLoad(context.Background(), key, func(ctx context.Context) (int, error) {
+		t.Error("cached value should not be loaded")
+		return 2, nil
+	})
+
+	// THEN
+	if err != nil || actual != 1 {
+		t.Errorf("expected cached value '1' but got '%d' and error: %v", actual, err)
+	}
+}
+
+func TestGetOrLoadShouldShareLoaderErrorWithoutCachingIt(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute)
+	defer cache.Close()
+	const key int = 1
+
+	loadErr := errors.New("load fail")
+	release := make(chan struct{})
+	var loaderCalls atomic.Int32
+
+	loader := func(ctx context.Context) (int, error) {
+		loaderCalls.Add(1)
+		<-release
+		return 0, loadErr
+	}
+
+	// WHEN
+	var callers sync.WaitGroup
+	errs := make([]error, 2)
+
+	for i := range errs {
+		callers.Add(1)
+		go func() {
+			defer callers.Done()
+			_, errs[i] = cache.GetOrLoad(context.Background(), key, loader)
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+	callers.Wait()
+
+	// THEN
+	for _, err := range errs {
+		if !errors.Is(err, loadErr) {
+			t.Errorf("expected loader error to be returned but got: %v", err)
+		}
+	}
+
+	if loaderCalls.Load() != 1 {
+		t.Errorf("expected a single loader call but got %d", loaderCalls.Load())
+	}
+
+	if _, isCached := cache.Get(key); isCached {
+		t.Error("failed load should not be cached")
+	}
+}
+
+func TestGetOrLoadShouldKeepLoadingForWaitersWhenStarterIsCancelled(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute)
+	defer cache.Close()
+	const key int = 1
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	loader := func(ctx context.Context) (int, error) {
+		close(started)
+		select {
+		case <-release:
+			return 42, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	starterCtx, cancelStarter := context.WithCancel(context.Background())
+	starterErr := make(chan error)
+	go func() {
+		_, err := cache.GetOrLoad(starterCtx, key, loader)
+		starterErr <- err
+	}()
+	<-started
+
+	waiterValue := make(chan int)
+	go func() {
+		value, _ := cache.GetOrLoad(context.Background(), key, loader)
+		waiterValue <- value
+	}()
+	time.Sleep(time.Millisecond * 20)
+
+	// WHEN
+	cancelStarter()
+
+	// THEN
+	if err := <-starterErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled starter should stop waiting with its context error but got: %v", err)
+	}
+
+	close(release)
+	if value := <-waiterValue; value != 42 {
+		t.Errorf("expected waiter to get loaded value '42' but got '%d'", value)
+	}
+}
+
+func TestGetOrLoadShouldCancelLoaderOnceEveryCallerIsCancelled(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute)
+	defer cache.Close()
+	const key int = 1
+
+	started := make(chan struct{}, 2)
+	loaderErr := make(chan error, 1)
+
+	loader := func(ctx context.Context) (int, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		loaderErr <- ctx.Err()
+		return 0, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var callers sync.WaitGroup
+	errs := make([]error, 2)
+
+	for i := range errs {
+		callers.Add(1)
+		go func() {
+			defer callers.Done()
+			_, errs[i] = cache.GetOrLoad(ctx, key, loader)
+		}()
+	}
+	<-started
+	time.Sleep(time.Millisecond * 20)
+
+	// WHEN
+	cancel()
+	callers.Wait()
+
+	// THEN
+	for _, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected cancelled callers to get their context error but got: %v", err)
+		}
+	}
+
+	select {
+	case err := <-loaderErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected loader context to be cancelled but got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("loader context should be cancelled once no caller waits for it")
+	}
+
+	if len(started) != 0 {
+		t.Error("concurrent callers should have shared a single loader call")
+	}
+}
+
+func TestGetOrLoadShouldBindLoaderToStarterDeadline(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute)
+	defer cache.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	expectedDeadline, _ := ctx.Deadline()
+
+	// WHEN
+	var loaderDeadline time.Time
+	cache.GetOrLoad(ctx, 1, func(loaderCtx context.Context) (int, error) {
+		loaderDeadline, _ = loaderCtx.Deadline()
+		return 1, nil
+	})
+
+	// THEN
+	if !loaderDeadline.Equal(expectedDeadline) {
+		t.Errorf("expected loader deadline %v but got %v", expectedDeadline, loaderDeadline)
+	}
+}
@@ -0,0 +1,275 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache bounded in size, evicting its least recently used entry when full.
+// Entries also expire after a time to live period, and are dropped when read once expired.
+// A shorter soft time to live can be set, after which entries are stale and get refreshed by GetOrRevalidate
+type LruTimeToLiveCache[K comparable, V any] struct {
+	entries map[K]*list.Element
+	// Most recently used entries are at the front
+	recency *list.List
+	mutex sync.Mutex
+	timeToLive time.Duration
+	softTimeToLive time.Duration
+	revalidations *revalidations[K]
+	loads *loadGroup[K, V]
+	stats statsCounters
+	maxEntries int
+}
+
+type lruEntry[K comparable, V any] struct {
+	key K
+	value V
+	addedAt time.Time
+	staleAt time.Time
+	expiresAt time.Time
+}
+
+func NewLruTimeToLiveCache[K comparable, V any](timeToLive time.Duration, maxEntries int) *LruTimeToLiveCache[K, V] {
+	return &LruTimeToLiveCache[K, V]{
+		entries: make(map[K]*list.Element),
+		recency: list.New(),
+		timeToLive: timeToLive,
+		softTimeToLive: timeToLive,
+		revalidations: newRevalidations[K](),
+		loads: newLoadGroup[K, V](),
+		maxEntries: max(1, maxEntries),
+	}
+}
+
+// Enables stale-while-revalidate: entries older than softTimeToLive are still served until their time to live ellapses,
+// but GetOrRevalidate refreshes them in background. Soft time to live is capped by the time to live
+func (cache *LruTimeToLiveCache[K, V]) WithSoftTimeToLive(softTimeToLive time.Duration) *LruTimeToLiveCache[K, V] {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.softTimeToLive = min(softTimeToLive, cache.timeToLive)
+	return cache
+}
+
+// Bounds the number of background refreshes run at the same time, stale entries being served as is while the bound is reached
+func (cache *LruTimeToLiveCache[K, V]) WithMaxConcurrentRefreshes(maxRefreshes int) *LruTimeToLiveCache[K, V] {
+	cache.revalidations.setMaxInProgress(maxRefreshes)
+	return cache
+}
+
+func (cache *LruTimeToLiveCache[K, V]) Add(key K, value V) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := time.Now()
+	staleAt := now.Add(cache.softTimeToLive)
+	expiresAt := now.Add(cache.timeToLive)
+
+	if element, exists := cache.entries[key]; exists {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.addedAt = now
+		entry.staleAt = staleAt
+		entry.expiresAt = expiresAt
+		cache.recency.MoveToFront(element)
+		return
+	}
+
+	cache.entries[key] = cache.recency.PushFront(&lruEntry[K, V]{key: key, value: value, addedAt: now, staleAt: staleAt, expiresAt: expiresAt})
+
+	for cache.recency.Len() > cache.maxEntries {
+		cache.evictOldest()
+	}
+}
+
+func (cache *LruTimeToLiveCache[K, V]) Get(key K) (V, bool) {
+	value, ok := cache.peek(key)
+	cache.stats.recordLookup(ok)
+
+	return value, ok
+}
+
+func (cache *LruTimeToLiveCache[K, V]) peek(key K) (V, bool) {
+	entry, ok := cache.getEntry(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	return entry.value, true
+}
+
+func (cache *LruTimeToLiveCache[K, V]) GetOrRevalidate(key K, refresh func() (V, error)) (V, bool) {
+	entry, ok := cache.getEntry(key)
+	cache.stats.recordLookup(ok)
+
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	if !time.Now().Before(entry.staleAt) {
+		revalidate(cache.revalidations, key, refresh, func(value V) {
+			cache.Add(key, value)
+		})
+	}
+
+	return entry.value, true
+}
+
+func (cache *LruTimeToLiveCache[K, V]) GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (V, error) {
+	return getOrLoad(ctx, cache, cache.loads, key, loader)
+}
+
+func (cache *LruTimeToLiveCache[K, V]) Load(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (V, error) {
+	return loadMissing(ctx, cache, cache.loads, key, loader)
+}
+
+// Gets a copy of the entry and marks it as most recently used, unless it has expired
+func (cache *LruTimeToLiveCache[K, V]) getEntry(key K) (lruEntry[K, V], bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, exists := cache.entries[key]
+	if !exists {
+		return lruEntry[K, V]{}, false
+	}
+
+	entry := element.Value.(*lruEntry[K, V])
+	if !time.Now().Before(entry.expiresAt) {
+		cache.remove(element)
+		cache.stats.expirations.Add(1)
+		return lruEntry[K, V]{}, false
+	}
+
+	cache.recency.MoveToFront(element)
+
+	return *entry, true
+}
+
+func (cache *LruTimeToLiveCache[K, V]) Delete(key K) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, exists := cache.entries[key]; exists {
+		cache.remove(element)
+	}
+}
+
+// Keys are listed from the most to the least recently used
+func (cache *LruTimeToLiveCache[K, V]) Keys() []K {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := time.Now()
+	keys := make([]K, 0, cache.recency.Len())
+
+	for element := cache.recency.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*lruEntry[K, V])
+
+		if now.Before(entry.expiresAt) {
+			keys = append(keys, entry.key)
+		}
+	}
+
+	return keys
+}
+
+func (cache *LruTimeToLiveCache[K, V]) FormattedKeys() []string {
+	return formatKeys(cache.Keys())
+}
+
+func (cache *LruTimeToLiveCache[K, V]) Clear() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	clearedCount := cache.recency.Len()
+	cache.entries = make(map[K]*list.Element)
+	cache.recency.Init()
+
+	return clearedCount
+}
+
+// Entries are listed from the least to the most recently used, so that restoring them keeps their recency
+func (cache *LruTimeToLiveCache[K, V]) Entries() []Entry[K, V] {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := time.Now()
+	entries := make([]Entry[K, V], 0, cache.recency.Len())
+
+	for element := cache.recency.Back(); element != nil; element = element.Prev() {
+		entry := element.Value.(*lruEntry[K, V])
+
+		if now.Before(entry.expiresAt) {
+			entries = append(entries, Entry[K, V]{Key: entry.key, Value: entry.value, AddedAt: entry.addedAt, StaleAt: entry.staleAt, ExpiresAt: entry.expiresAt})
+		}
+	}
+
+	return entries
+}
+
+// Entries are restored as most recently used in turn, evicting the least recently used ones if there are too many
+func (cache *LruTimeToLiveCache[K, V]) Restore(entries []Entry[K, V]) int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := time.Now()
+	restoredCount := 0
+
+	for _, entry := range entries {
+		entry = entry.clamped(now, cache.timeToLive, cache.softTimeToLive)
+		if entry.expired(now) {
+			continue
+		}
+
+		if element, exists := cache.entries[entry.Key]; exists {
+			cache.remove(element)
+		}
+
+		cache.entries[entry.Key] = cache.recency.PushFront(&lruEntry[K, V]{
+			key: entry.Key,
+			value: entry.Value,
+			addedAt: entry.AddedAt,
+			staleAt: entry.StaleAt,
+			expiresAt: entry.ExpiresAt,
+		})
+		restoredCount++
+
+		for cache.recency.Len() > cache.maxEntries {
+			cache.evictOldest()
+		}
+	}
+
+	return restoredCount
+}
+
+func (cache *LruTimeToLiveCache[K, V]) Stats() Stats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := time.Now()
+	var oldestEntryAge time.Duration
+
+	for element := cache.recency.Front(); element != nil; element = element.Next() {
+		oldestEntryAge = max(oldestEntryAge, now.Sub(element.Value.(*lruEntry[K, V]).addedAt))
+	}
+
+	return cache.stats.snapshot(cache.recency.Len(), oldestEntryAge)
+}
+
+// Expired entries are dropped on read, so no background resource has to be released
+func (cache *LruTimeToLiveCache[K, V]) Close() {
+}
+
+func (cache *LruTimeToLiveCache[K, V]) evictOldest() {
+	cache.remove(cache.recency.Back())
+	cache.stats.evictions.Add(1)
+}
+
+func (cache *LruTimeToLiveCache[K, V]) remove(element *list.Element) {
+	cache.recency.Remove(element)
+	delete(cache.entries, element.Value.(*lruEntry[K, V]).key)
+}
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLruAddShouldStoreValueInCache(t *testing.T) {
+	// GIVEN
+	var cache = NewLruTimeToLiveCache[int, int](time.Minute, 10)
+	const key = 1
+	const value = 2
+
+	// WHEN
+	cache.Add(key, value)
+
+	// THEN
+	actual, isCached := cache.Get(key)
+
+	if !isCached {
+		t.Error("value should be cached")
+	} else if actual != value {
+		t.Errorf("expected cached value '%d' but got '%d' instead", value, actual)
+	}
+}
+
+func TestLruAddShouldEvictLeastRecentlyAddedEntryWhenFull(t *testing.T) {
+	// GIVEN
+	var cache = NewLruTimeToLiveCache[int, int](time.Minute, 2)
+	cache.Add(1, 1)
+	cache.Add(2, 2)
+
+	// WHEN
+	cache.Add(3, 3)
+
+	// THEN
+	if _, isCached := cache.Get(1); isCached {
+		t.Error("oldest entry should have been evicted")
+	}
+
+	for _, key := range []int{2, 3} {
+		if _, isCached := cache.Get(key); !isCached {
+			t.Errorf("entry '%d' should still be cached", key)
+		}
+	}
+}
+
+func TestLruGetShouldProtectEntryFromEviction(t *testing.T) {
+	// GIVEN
+	var cache = NewLruTimeToLiveCache[int, int](time.Minute, 2)
+	cache.Add(1, 1)
+	cache.Add(2, 2)
+	cache.Get(1)
+
+	// WHEN
+	cache.Add(3, 3)
+
+	// THEN
+	if _, isCached := cache.Get(2); isCached {
+		t.Error("least recently used entry should have been evicted")
+	}
+
+	for _, key := range []int{1, 3} {
+		if _, isCached := cache.Get(key); !isCached {
+			t.Errorf("entry '%d' should still be cached", key)
+		}
+	}
+}
+
+func TestLruAddShouldRefreshExistingEntryWithoutEviction(t *testing.T) {
+	// GIVEN
+	var cache = NewLruTimeToLiveCache[int, int](time.Minute, 2)
+	cache.Add(1, 1)
+	cache.Add(2, 2)
+
+	// WHEN
+	cache.Add(1, 10)
+	cache.Add(3, 3)
+
+	// THEN
+	actual, isCached := cache.Get(1)
+	if !isCached {
+		t.Error("refreshed entry should still be cached")
+	} else if actual != 10 {
+		t.Errorf("expected refreshed value '10' but got '%d'", actual)
+	}
+
+	if _, isCached := cache.Get(2); isCached {
+		t.Error("least recently used entry should have been evicted")
+	}
+}
+
+func TestLruDeleteShouldRemoveValueFromCache(t *testing.T) {
+	// GIVEN
+	var cache = NewLruTimeToLiveCache[int, int](time.Minute, 10)
+	cache.Add(1, 2)
+
+	// WHEN
+	cache.Delete(1)
+
+	// THEN
+	if _, isCached := cache.Get(1); isCached {
+		t.Error("no value should be cached")
+	}
+}
+
+func TestLruGetShouldNotReturnEntriesAfterTimeToLiveEllapsed(t *testing.T) {
+	// GIVEN
+	var cache = NewLruTimeToLiveCache[int, int](time.Nanosecond * 10, 10)
+	cache.Add(1, 2)
+
+	// WHEN
+	time.Sleep(time.Microsecond * 500)
+
+	// THEN
+	if _, isCached := cache.Get(1); isCached {
+		t.Error("no value should be cached")
+	}
+
+	if cache.recency.Len() != 0 {
+		t.Error("expired entry should have been removed")
+	}
+}
+
+func TestLruStatsShouldCountLookupsEvictionsAndExpirations(t *testing.T) {
+	// GIVEN
+	var cache = NewLruTimeToLiveCache[int, int](time.Millisecond * 50, 2)
+	cache.Add(1, 1)
+	cache.Add(2, 2)
+	cache.Add(3, 3)
+
+	cache.Get(2)
+	cache.Get(1)
+	time.Sleep(time.Millisecond * 60)
+	cache.Get(3)
+
+	// WHEN
+	stats := cache.Stats()
+
+	// THEN
+	expected := Stats{Hits: 1, Misses: 2, Evictions: 1, Expirations: 1, Size: 1}
+	if stats.Hits != expected.Hits || stats.Misses != expected.Misses || stats.Evictions != expected.Evictions ||
+		stats.Expirations != expected.Expirations || stats.Size != expected.Size {
+		t.Errorf("expected stats %+v but got %+v", expected, stats)
+	}
+
+	if stats.OldestEntryAge < time.Millisecond * 60 {
+		t.Errorf("expected oldest entry to be at least 60ms old but got %v", stats.OldestEntryAge)
+	}
+}
+
+func TestLruRestoreShouldKeepRecencyOfListedEntries(t *testing.T) {
+	// GIVEN
+	var savedCache = NewLruTimeToLiveCache[int, int](time.Minute, 3)
+	savedCache.Add(1, 1)
+	savedCache.Add(2, 2)
+	savedCache.Add(3, 3)
+	savedCache.Get(1)
+
+	var restoredCache = NewLruTimeToLiveCache[int, int](time.Minute, 2)
+
+	// WHEN
+	restoredCache.Restore(savedCache.Entries())
+
+	// THEN
+	if _, isCached := restoredCache.Get(2); isCached {
+		t.Error("least recently used entry should have been evicted")
+	}
+
+	for _, key := range []int{1, 3} {
+		if _, isCached := restoredCache.Get(key); !isCached {
+			t.Errorf("entry '%d' should have been restored", key)
+		}
+	}
+}
+
+func TestLruClearShouldRemoveEveryEntry(t *testing.T) {
+	// GIVEN
+	var cache = NewLruTimeToLiveCache[int, int](time.Minute, 2)
+	cache.Add(1, 1)
+	cache.Add(2, 2)
+
+	// WHEN
+	clearedCount := cache.Clear()
+
+	// THEN
+	if clearedCount != 2 {
+		t.Errorf("expected 2 cleared entries but got %d", clearedCount)
+	} else if len(cache.Keys()) != 0 {
+		t.Errorf("no key should be left but got %v", cache.Keys())
+	}
+
+	cache.Add(3, 3)
+	cache.Add(4, 4)
+	if keys := cache.Keys(); len(keys) != 2 || keys[0] != 4 {
+		t.Errorf("expected keys [4 3] but got %v", keys)
+	}
+}
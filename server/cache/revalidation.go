@@ -0,0 +1,50 @@
+package cache
+
+import "sync"
+
+// Max number of background refreshes a cache runs at the same time, unless set with WithMaxConcurrentRefreshes
+const defaultMaxConcurrentRefreshes int = 8
+
+// Tracks background refreshes of stale entries, so that a single refresh runs at a time for a given key,
+// and at most maxInProgress overall
+type revalidations[K comparable] struct {
+	mutex sync.Mutex
+	inProgress map[K]bool
+	maxInProgress int
+}
+
+func newRevalidations[K comparable]() *revalidations[K] {
+	return &revalidations[K]{inProgress: make(map[K]bool), maxInProgress: defaultMaxConcurrentRefreshes}
+}
+
+func (r *revalidations[K]) setMaxInProgress(maxInProgress int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.maxInProgress = max(1, maxInProgress)
+}
+
+// Runs refresh in background unless a refresh of key is already running, or as many refreshes as allowed are.
+// Skipped refreshes are retried by the next lookups of the still stale entry.
+// Refreshed value is handed to onRefreshed, while failures are ignored so that the stale value is kept until it expires
+func revalidate[K comparable, V any](r *revalidations[K], key K, refresh func() (V, error), onRefreshed func(V)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.inProgress[key] || len(r.inProgress) >= r.maxInProgress {
+		return
+	}
+	r.inProgress[key] = true
+
+	go func() {
+		value, err := refresh()
+
+		if err == nil {
+			onRefreshed(value)
+		}
+
+		r.mutex.Lock()
+		delete(r.inProgress, key)
+		r.mutex.Unlock()
+	}()
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache entry along with its timing, so that it can be restored with its remaining time to live
+type Entry[K comparable, V any] struct {
+	Key K
+	Value V
+	AddedAt time.Time
+	StaleAt time.Time
+	ExpiresAt time.Time
+}
+
+func (entry Entry[K, V]) expired(now time.Time) bool {
+	return !now.Before(entry.ExpiresAt)
+}
+
+// Entry restored at now into a cache whose time to live may have been shortened since it was saved:
+// it is not kept, nor served fresh, longer than the cache currently allows
+func (entry Entry[K, V]) clamped(now time.Time, timeToLive time.Duration, softTimeToLive time.Duration) Entry[K, V] {
+	entry.ExpiresAt = minTime(entry.ExpiresAt, now.Add(timeToLive))
+	entry.StaleAt = minTime(minTime(entry.StaleAt, now.Add(softTimeToLive)), entry.ExpiresAt)
+
+	return entry
+}
+
+func minTime(time time.Time, otherTime time.Time) time.Time {
+	if otherTime.Before(time) {
+		return otherTime
+	}
+
+	return time
+}
+
+// Saves entries of named caches to a single file, and restores them from it.
+// Entries are gob encoded, so cached values must be encodable by encoding/gob
+type SnapshotStore struct {
+	path string
+	caches map[string]snapshotable
+}
+
+// Cache whose entries can be encoded and decoded, regardless of their types
+type snapshotable interface {
+	encodeEntries() ([]byte, error)
+	decodeEntries(encoded []byte) (int, error)
+}
+
+type snapshotableCache[K comparable, V any] struct {
+	cache Cache[K, V]
+}
+
+func NewSnapshotStore(path string) *SnapshotStore {
+	return &SnapshotStore{path: path, caches: make(map[string]snapshotable)}
+}
+
+// Adds cache to the snapshots of store, under name. Go methods cannot have type parameters, hence this function
+func RegisterSnapshot[K comparable, V any](store *SnapshotStore, name string, cache Cache[K, V]) {
+	store.caches[name] = snapshotableCache[K, V]{cache: cache}
+}
+
+// Writes entries of every registered cache to the snapshot file.
+// The file is replaced at once, so that a crash while saving does not corrupt the previous snapshot
+func (store *SnapshotStore) Save() error {
+	snapshot := make(map[string][]byte, len(store.caches))
+
+	for name, cache := range store.caches {
+		encoded, err := cache.encodeEntries()
+		if err != nil {
+			return fmt.Errorf("could not encode entries of cache '%s': %w", name, err)
+		}
+		snapshot[name] = encoded
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(store.path), filepath.Base(store.path) + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := gob.NewEncoder(file).Encode(snapshot); err != nil {
+		file.Close()
+		return err
+	} else if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), store.path)
+}
+
+// Restores entries of registered caches from the snapshot file, discarding expired ones, and returns the number of restored entries.
+// A missing snapshot file restores nothing, and caches missing from the snapshot are left untouched
+func (store *SnapshotStore) Load() (int, error) {
+	content, err := os.ReadFile(store.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	var snapshot map[string][]byte
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&snapshot); err != nil {
+		return 0, fmt.Errorf("could not decode snapshot '%s': %w", store.path, err)
+	}
+
+	restoredCount := 0
+
+	for name, cache := range store.caches {
+		encoded, isSnapshotted := snapshot[name]
+		if !isSnapshotted {
+			continue
+		}
+
+		restored, err := cache.decodeEntries(encoded)
+		if err != nil {
+			return restoredCount, fmt.Errorf("could not decode entries of cache '%s': %w", name, err)
+		}
+		restoredCount += restored
+	}
+
+	return restoredCount, nil
+}
+
+func (snapshotable snapshotableCache[K, V]) encodeEntries() ([]byte, error) {
+	var encoded bytes.Buffer
+
+	if err := gob.NewEncoder(&encoded).Encode(snapshotable.cache.Entries()); err != nil {
+		return nil, err
+	}
+
+	return encoded.Bytes(), nil
+}
+
+func (snapshotable snapshotableCache[K, V]) decodeEntries(encoded []byte) (int, error) {
+	var entries []Entry[K, V]
+
+	if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&entries); err != nil {
+		return 0, err
+	}
+
+	return snapshotable.cache.Restore(entries), nil
+}
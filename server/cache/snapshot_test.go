@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type snapshottedValue struct {
+	Name string
+}
+
+func TestSnapshotStoreShouldRestoreEntriesWithRemainingTimeToLive(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	savedCache := NewTimeToLiveCache[int, *snapshottedValue](time.Minute)
+	defer savedCache.Close()
+	savedCache.Add(1, &snapshottedValue{Name: "one"})
+	savedCache.Add(2, nil)
+	savedStore := NewSnapshotStore(path)
+	RegisterSnapshot(savedStore, "values", savedCache)
+
+	restoredCache := NewLruTimeToLiveCache[int, *snapshottedValue](time.Hour, 10)
+	restoredStore := NewSnapshotStore(path)
+	RegisterSnapshot(restoredStore, "values", restoredCache)
+
+	// WHEN
+	if err := savedStore.Save(); err != nil {
+		t.Fatalf("snapshot should be saved but got: %v", err)
+	}
+	restoredCount, err := restoredStore.Load()
+
+	// THEN
+	if err != nil {
+		t.Fatalf("snapshot should be loaded but got: %v", err)
+	} else if restoredCount != 2 {
+		t.Errorf("expected 2 restored entries but got %d", restoredCount)
+	}
+
+	if value, isCached := restoredCache.Get(1); !isCached || value == nil || value.Name != "one" {
+		t.Errorf("expected restored value 'one' but got %+v", value)
+	}
+
+	if value, isCached := restoredCache.Get(2); !isCached || value != nil {
+		t.Errorf("expected restored nil value but got %+v", value)
+	}
+
+	for _, entry := range restoredCache.Entries() {
+		if entry.ExpiresAt.After(time.Now().Add(time.Minute)) {
+			t.Errorf("entry '%d' should keep the remaining time to live of its saved cache", entry.Key)
+		}
+	}
+}
+
+func TestSnapshotStoreShouldDiscardExpiredEntries(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	savedCache := NewLruTimeToLiveCache[int, int](time.Millisecond * 20, 10)
+	savedCache.Add(1, 1)
+	savedStore := NewSnapshotStore(path)
+	RegisterSnapshot(savedStore, "values", savedCache)
+
+	if err := savedStore.Save(); err != nil {
+		t.Fatalf("snapshot should be saved but got: %v", err)
+	}
+
+	restoredCache := NewTimeToLiveCache[int, int](time.Hour)
+	defer restoredCache.Close()
+	restoredStore := NewSnapshotStore(path)
+	RegisterSnapshot(restoredStore, "values", restoredCache)
+
+	// WHEN
+	time.Sleep(time.Millisecond * 30)
+	restoredCount, err := restoredStore.Load()
+
+	// THEN
+	if err != nil {
+		t.Fatalf("snapshot should be loaded but got: %v", err)
+	} else if restoredCount != 0 {
+		t.Errorf("expired entry should not be restored but %d entries were", restoredCount)
+	}
+
+	if _, isCached := restoredCache.Get(1); isCached {
+		t.Error("expired entry should not be cached")
+	}
+}
+
+func TestSnapshotStoreShouldClampRestoredEntriesToShortenedTimeToLive(t *testing.T) {
+	caches := map[string]Cache[int, int]{
+		"time to live cache": NewTimeToLiveCache[int, int](time.Minute).WithSoftTimeToLive(time.Second * 30),
+		"lru time to live cache": NewLruTimeToLiveCache[int, int](time.Minute, 10).WithSoftTimeToLive(time.Second * 30),
+	}
+
+	for name, restoredCache := range caches {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			defer restoredCache.Close()
+			path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+			savedCache := NewTimeToLiveCache[int, int](time.Hour).WithSoftTimeToLive(time.Hour)
+			defer savedCache.Close()
+			savedCache.Add(1, 1)
+			savedStore := NewSnapshotStore(path)
+			RegisterSnapshot(savedStore, "values", savedCache)
+
+			if err := savedStore.Save(); err != nil {
+				t.Fatalf("snapshot should be saved but got: %v", err)
+			}
+
+			restoredStore := NewSnapshotStore(path)
+			RegisterSnapshot(restoredStore, "values", restoredCache)
+
+			// WHEN
+			restoredCount, err := restoredStore.Load()
+
+			// THEN
+			if err != nil || restoredCount != 1 {
+				t.Fatalf("entry should be restored but got %d entries and error: %v", restoredCount, err)
+			}
+
+			entry := restoredCache.Entries()[0]
+			if entry.ExpiresAt.After(time.Now().Add(time.Minute)) {
+				t.Errorf("restored entry should not outlive the time to live of its cache but expires at %v", entry.ExpiresAt)
+			}
+
+			if entry.StaleAt.After(time.Now().Add(time.Second * 30)) {
+				t.Errorf("restored entry should not be fresh longer than the soft time to live of its cache but is until %v", entry.StaleAt)
+			}
+		})
+	}
+}
+
+func TestSnapshotStoreShouldRestoreNothingWithoutSnapshotFile(t *testing.T) {
+	// GIVEN
+	store := NewSnapshotStore(filepath.Join(t.TempDir(), "missing.snapshot"))
+	RegisterSnapshot(store, "values", NewLruTimeToLiveCache[int, int](time.Minute, 10))
+
+	// WHEN
+	restoredCount, err := store.Load()
+
+	// THEN
+	if err != nil || restoredCount != 0 {
+		t.Errorf("expected nothing restored and no error but got %d entries and error: %v", restoredCount, err)
+	}
+}
+
+func TestSnapshotStoreShouldFailOnCorruptedSnapshot(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "corrupted.snapshot")
+	if err := os.WriteFile(path, []byte("not a snapshot"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewSnapshotStore(path)
+	RegisterSnapshot(store, "values", NewLruTimeToLiveCache[int, int](time.Minute, 10))
+
+	// WHEN
+	_, err := store.Load()
+
+	// THEN
+	if err == nil {
+		t.Error("corrupted snapshot should not be loaded")
+	}
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Usage statistics of a cache since its creation
+type Stats struct {
+	Hits uint64
+	Misses uint64
+	// Entries removed to make room for new ones
+	Evictions uint64
+	// Entries removed because their time to live ellapsed
+	Expirations uint64
+	Size int
+	// Age of the earliest added entry still in cache, 0 when cache is empty
+	OldestEntryAge time.Duration
+}
+
+// Counters updated by caches as they are used, safe for concurrent use
+type statsCounters struct {
+	hits atomic.Uint64
+	misses atomic.Uint64
+	evictions atomic.Uint64
+	expirations atomic.Uint64
+}
+
+func (counters *statsCounters) recordLookup(isCached bool) {
+	if isCached {
+		counters.hits.Add(1)
+	} else {
+		counters.misses.Add(1)
+	}
+}
+
+func (counters *statsCounters) snapshot(size int, oldestEntryAge time.Duration) Stats {
+	return Stats{
+		Hits: counters.hits.Load(),
+		Misses: counters.misses.Load(),
+		Evictions: counters.evictions.Load(),
+		Expirations: counters.expirations.Load(),
+		Size: size,
+		OldestEntryAge: oldestEntryAge,
+	}
+}
+
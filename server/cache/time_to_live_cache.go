@@ -1,61 +1,340 @@
 package cache
 
 import (
+	"container/heap"
+	"context"
 	"sync"
 	"time"
 )
 
-// Cache that automatically delete its entries after a time to live period
+// Cache that automatically delete its entries after a time to live period.
+// Expired entries are never returned, and a single sweeper removes them from memory once their time is up.
+// A shorter soft time to live can be set, after which entries are stale and get refreshed by GetOrRevalidate
 type TimeToLiveCache[K comparable, V any] struct {
-	data map[K]V
+	data map[K]timedEntry[V]
+	// Entries expiration times, the earliest one on top. Each entry has a single expiration, moved when it is overwritten
+	// and removed along with it, so that the heap never holds more expirations than entries
+	expirations expirationHeap[K]
+	expirationsByKey map[K]*expiration[K]
 	mutex sync.RWMutex
 	timeToLive time.Duration
-	timeToLiveTimers map[K]*time.Timer
+	softTimeToLive time.Duration
+	revalidations *revalidations[K]
+	loads *loadGroup[K, V]
+	stats statsCounters
+	// Wakes the sweeper up when an earlier expiration is scheduled
+	wakeUpSweeper chan struct{}
+	closed chan struct{}
+	closeOnce sync.Once
+}
+
+type timedEntry[V any] struct {
+	value V
+	addedAt time.Time
+	staleAt time.Time
+	expiresAt time.Time
 }
 
 func NewTimeToLiveCache[K comparable, V any](timeToLive time.Duration) *TimeToLiveCache[K, V] {
-	return &TimeToLiveCache[K, V]{
-		data: make(map[K]V),
+	cache := &TimeToLiveCache[K, V]{
+		data: make(map[K]timedEntry[V]),
+		expirationsByKey: make(map[K]*expiration[K]),
 		timeToLive: timeToLive,
-		timeToLiveTimers: make(map[K]*time.Timer),
+		softTimeToLive: timeToLive,
+		revalidations: newRevalidations[K](),
+		loads: newLoadGroup[K, V](),
+		wakeUpSweeper: make(chan struct{}, 1),
+		closed: make(chan struct{}),
 	}
+
+	go cache.sweep()
+
+	return cache
 }
 
-func (cache *TimeToLiveCache[K, V]) Add(key K, value V) {
+// Enables stale-while-revalidate: entries older than softTimeToLive are still served until their time to live ellapses,
+// but GetOrRevalidate refreshes them in background. Soft time to live is capped by the time to live
+func (cache *TimeToLiveCache[K, V]) WithSoftTimeToLive(softTimeToLive time.Duration) *TimeToLiveCache[K, V] {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 
-	cache.data[key] = value
+	cache.softTimeToLive = min(softTimeToLive, cache.timeToLive)
+	return cache
+}
 
-	cache.timeToLiveTimers[key] = time.AfterFunc(cache.timeToLive, func() {
-		cache.Delete(key)
-	})
+// Bounds the number of background refreshes run at the same time, stale entries being served as is while the bound is reached
+func (cache *TimeToLiveCache[K, V]) WithMaxConcurrentRefreshes(maxRefreshes int) *TimeToLiveCache[K, V] {
+	cache.revalidations.setMaxInProgress(maxRefreshes)
+	return cache
 }
 
-func (cache *TimeToLiveCache[K, V]) Get(key K) (V, bool) {
+func (cache *TimeToLiveCache[K, V]) Add(key K, value V) {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 
-	value, ok := cache.data[key]
+	now := time.Now()
+	expiresAt := now.Add(cache.timeToLive)
+	cache.data[key] = timedEntry[V]{value: value, addedAt: now, staleAt: now.Add(cache.softTimeToLive), expiresAt: expiresAt}
+
+	cache.scheduleExpiration(key, expiresAt)
+
+	if cache.expirations[0].expiresAt.Equal(expiresAt) {
+		select {
+		case cache.wakeUpSweeper <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Schedules the expiration of key, moving its previous one if any. Must be called with the lock held
+func (cache *TimeToLiveCache[K, V]) scheduleExpiration(key K, expiresAt time.Time) {
+	if scheduled, isScheduled := cache.expirationsByKey[key]; isScheduled {
+		scheduled.expiresAt = expiresAt
+		heap.Fix(&cache.expirations, scheduled.index)
+		return
+	}
+
+	scheduled := &expiration[K]{key: key, expiresAt: expiresAt}
+	heap.Push(&cache.expirations, scheduled)
+	cache.expirationsByKey[key] = scheduled
+}
+
+// Removes the entry of key along with its expiration. Must be called with the lock held
+func (cache *TimeToLiveCache[K, V]) remove(key K) {
+	delete(cache.data, key)
+
+	if scheduled, isScheduled := cache.expirationsByKey[key]; isScheduled {
+		heap.Remove(&cache.expirations, scheduled.index)
+		delete(cache.expirationsByKey, key)
+	}
+}
+
+func (cache *TimeToLiveCache[K, V]) Get(key K) (V, bool) {
+	value, ok := cache.peek(key)
+	cache.stats.recordLookup(ok)
+
 	return value, ok
 }
 
+func (cache *TimeToLiveCache[K, V]) peek(key K) (V, bool) {
+	entry, ok := cache.getEntry(key)
+	return entry.value, ok
+}
+
+func (cache *TimeToLiveCache[K, V]) GetOrRevalidate(key K, refresh func() (V, error)) (V, bool) {
+	entry, ok := cache.getEntry(key)
+	cache.stats.recordLookup(ok)
+
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	if !time.Now().Before(entry.staleAt) {
+		revalidate(cache.revalidations, key, refresh, func(value V) {
+			cache.Add(key, value)
+		})
+	}
+
+	return entry.value, true
+}
+
+// Gets a copy of the entry, unless it has expired
+func (cache *TimeToLiveCache[K, V]) getEntry(key K) (timedEntry[V], bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	entry, ok := cache.data[key]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return timedEntry[V]{}, false
+	}
+
+	return entry, true
+}
+
+func (cache *TimeToLiveCache[K, V]) GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (V, error) {
+	return getOrLoad(ctx, cache, cache.loads, key, loader)
+}
+
+func (cache *TimeToLiveCache[K, V]) Load(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (V, error) {
+	return loadMissing(ctx, cache, cache.loads, key, loader)
+}
+
 func (cache *TimeToLiveCache[K, V]) Delete(key K) {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 
-	delete(cache.data, key)
+	cache.remove(key)
+}
+
+func (cache *TimeToLiveCache[K, V]) Keys() []K {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	now := time.Now()
+	keys := make([]K, 0, len(cache.data))
+
+	for key, entry := range cache.data {
+		if now.Before(entry.expiresAt) {
+			keys = append(keys, key)
+		}
+	}
 
-	cache.stopTtlTimer(key)
+	return keys
 }
 
-// Stops and deletes timer associated to key. 
-// Used to prevent timer from trigerring entry deletion while it has already been done
-func (cache *TimeToLiveCache[K, V]) stopTtlTimer(key K) {
-	ttlTimer, timerExists := cache.timeToLiveTimers[key]
-	
-	if timerExists {
-		ttlTimer.Stop()
-		delete(cache.timeToLiveTimers, key)
+func (cache *TimeToLiveCache[K, V]) FormattedKeys() []string {
+	return formatKeys(cache.Keys())
+}
+
+func (cache *TimeToLiveCache[K, V]) Clear() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	clearedCount := len(cache.data)
+	cache.data = make(map[K]timedEntry[V])
+	cache.expirations = nil
+	cache.expirationsByKey = make(map[K]*expiration[K])
+
+	return clearedCount
+}
+
+func (cache *TimeToLiveCache[K, V]) Entries() []Entry[K, V] {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	now := time.Now()
+	entries := make([]Entry[K, V], 0, len(cache.data))
+
+	for key, entry := range cache.data {
+		if now.Before(entry.expiresAt) {
+			entries = append(entries, Entry[K, V]{Key: key, Value: entry.value, AddedAt: entry.addedAt, StaleAt: entry.staleAt, ExpiresAt: entry.expiresAt})
+		}
+	}
+
+	return entries
+}
+
+func (cache *TimeToLiveCache[K, V]) Restore(entries []Entry[K, V]) int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := time.Now()
+	restoredCount := 0
+
+	for _, entry := range entries {
+		entry = entry.clamped(now, cache.timeToLive, cache.softTimeToLive)
+		if entry.expired(now) {
+			continue
+		}
+
+		cache.data[entry.Key] = timedEntry[V]{value: entry.Value, addedAt: entry.AddedAt, staleAt: entry.StaleAt, expiresAt: entry.ExpiresAt}
+		cache.scheduleExpiration(entry.Key, entry.ExpiresAt)
+		restoredCount++
+	}
+
+	select {
+	case cache.wakeUpSweeper <- struct{}{}:
+	default:
+	}
+
+	return restoredCount
+}
+
+func (cache *TimeToLiveCache[K, V]) Stats() Stats {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	now := time.Now()
+	var oldestEntryAge time.Duration
+
+	for _, entry := range cache.data {
+		oldestEntryAge = max(oldestEntryAge, now.Sub(entry.addedAt))
+	}
+
+	return cache.stats.snapshot(len(cache.data), oldestEntryAge)
+}
+
+// Stops the sweeper. Entries still expire once closed, but are only removed from memory when deleted
+func (cache *TimeToLiveCache[K, V]) Close() {
+	cache.closeOnce.Do(func() {
+		close(cache.closed)
+	})
+}
+
+// Removes entries as they expire, sleeping until the next expiration in between
+func (cache *TimeToLiveCache[K, V]) sweep() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		nextExpiration, hasNext := cache.removeExpiredEntries(time.Now())
+
+		var nextSweep <-chan time.Time
+		if hasNext {
+			timer.Reset(time.Until(nextExpiration))
+			nextSweep = timer.C
+		}
+
+		select {
+		case <-nextSweep:
+		case <-cache.wakeUpSweeper:
+		case <-cache.closed:
+			return
+		}
+	}
+}
+
+// Removes entries expired at given time, and returns the time of the next expiration if any
+func (cache *TimeToLiveCache[K, V]) removeExpiredEntries(now time.Time) (time.Time, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for len(cache.expirations) > 0 && !now.Before(cache.expirations[0].expiresAt) {
+		cache.remove(cache.expirations[0].key)
+		cache.stats.expirations.Add(1)
 	}
-}
\ No newline at end of file
+
+	if len(cache.expirations) == 0 {
+		return time.Time{}, false
+	}
+
+	return cache.expirations[0].expiresAt, true
+}
+
+type expiration[K comparable] struct {
+	key K
+	expiresAt time.Time
+	// Position in the heap, for the expiration to be moved or removed when its entry is overwritten or deleted
+	index int
+}
+
+// Min-heap of expirations, implementing heap.Interface
+type expirationHeap[K comparable] []*expiration[K]
+
+func (h expirationHeap[K]) Len() int {
+	return len(h)
+}
+
+func (h expirationHeap[K]) Less(i, j int) bool {
+	return h[i].expiresAt.Before(h[j].expiresAt)
+}
+
+func (h expirationHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expirationHeap[K]) Push(x any) {
+	pushed := x.(*expiration[K])
+	pushed.index = len(*h)
+	*h = append(*h, pushed)
+}
+
+func (h *expirationHeap[K]) Pop() any {
+	old := *h
+	last := old[len(old) - 1]
+	*h = old[:len(old) - 1]
+	return last
+}
@@ -1,13 +1,17 @@
 package cache
 
 import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
 func TestAddShouldStoreValueInCache(t *testing.T) {
 	// GIVEN
-	var cache = NewTimeToLiveCache[int, int](10)
+	var cache = NewTimeToLiveCache[int, int](time.Minute)
 	const key = 1
 	const value = 2
 
@@ -26,7 +30,7 @@ func TestAddShouldStoreValueInCache(t *testing.T) {
 
 func TestAddShouldStoreValueInCacheEvenIfNil(t *testing.T) {
 	// GIVEN
-	var cache = NewTimeToLiveCache[int, *int](10)
+	var cache = NewTimeToLiveCache[int, *int](time.Minute)
 	const key int = 1
 
 	// WHEN
@@ -44,7 +48,7 @@ func TestAddShouldStoreValueInCacheEvenIfNil(t *testing.T) {
 
 func TestGetShouldNotReturnValueIfNotCached(t *testing.T) {
 	// GIVEN
-	var cache = NewTimeToLiveCache[int, int](10)
+	var cache = NewTimeToLiveCache[int, int](time.Minute)
 	const key int = 1
 
 	// WHEN
@@ -59,7 +63,7 @@ func TestGetShouldNotReturnValueIfNotCached(t *testing.T) {
 
 func TestDeleteShouldRemoveValueFromCache(t *testing.T) {
 	// GIVEN
-	var cache = NewTimeToLiveCache[int, int](10)
+	var cache = NewTimeToLiveCache[int, int](time.Minute)
 	const key int = 1
 	const value int = 2
 
@@ -93,8 +97,454 @@ func TestDeleteEntriesAfterTimeToLiveEllapsed(t *testing.T) {
 		t.Error("no value should be cached")
 	}
 
-	_, timeExists := cache.timeToLiveTimers[key]
-	if timeExists {
-		t.Error("no timer should exist after time to live")
+	cache.mutex.RLock()
+	_, entryExists := cache.data[key]
+	cache.mutex.RUnlock()
+	if entryExists {
+		t.Error("no entry should exist after time to live")
 	}
-}
\ No newline at end of file
+}
+
+func TestAddShouldPostponeExpirationOfOverwrittenEntry(t *testing.T) {
+	// GIVEN
+	timeToLive := time.Millisecond * 50
+	cache := NewTimeToLiveCache[int, int](timeToLive)
+	defer cache.Close()
+	const key int = 1
+
+	cache.Add(key, 1)
+	time.Sleep(timeToLive / 2)
+
+	// WHEN
+	cache.Add(key, 2)
+	time.Sleep(timeToLive * 3 / 4)
+
+	// THEN
+	actual, isCached := cache.Get(key)
+	if !isCached {
+		t.Error("overwritten value should not expire with the previous one")
+	} else if actual != 2 {
+		t.Errorf("expected cached value '2' but got '%d' instead", actual)
+	}
+}
+
+func TestGetShouldNotReturnExpiredEntryBeforeItIsSwept(t *testing.T) {
+	// GIVEN
+	timeToLive := time.Millisecond
+	cache := NewTimeToLiveCache[int, int](timeToLive)
+	const key int = 1
+
+	// sweeper is stopped so that only the lazy check can hide the entry
+	cache.Close()
+	cache.Add(key, 2)
+
+	// WHEN
+	time.Sleep(timeToLive * 2)
+
+	// THEN
+	if _, isCached := cache.Get(key); isCached {
+		t.Error("expired value should not be returned")
+	}
+}
+
+func TestCloseShouldStopSweeper(t *testing.T) {
+	// GIVEN
+	timeToLive := time.Millisecond
+	cache := NewTimeToLiveCache[int, int](timeToLive)
+	const key int = 1
+
+	// WHEN
+	cache.Close()
+	cache.Add(key, 2)
+	time.Sleep(timeToLive * 10)
+
+	// THEN
+	cache.mutex.RLock()
+	_, entryExists := cache.data[key]
+	cache.mutex.RUnlock()
+	if !entryExists {
+		t.Error("expired entry should not be removed once cache is closed")
+	}
+}
+
+// Previous design of TimeToLiveCache, scheduling a timer per entry. Only kept to benchmark against it
+type timerTimeToLiveCache[K comparable, V any] struct {
+	data map[K]V
+	mutex sync.RWMutex
+	timeToLive time.Duration
+	timeToLiveTimers map[K]*time.Timer
+}
+
+func newTimerTimeToLiveCache[K comparable, V any](timeToLive time.Duration) *timerTimeToLiveCache[K, V] {
+	return &timerTimeToLiveCache[K, V]{
+		data: make(map[K]V),
+		timeToLive: timeToLive,
+		timeToLiveTimers: make(map[K]*time.Timer),
+	}
+}
+
+func (cache *timerTimeToLiveCache[K, V]) Add(key K, value V) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.data[key] = value
+
+	cache.timeToLiveTimers[key] = time.AfterFunc(cache.timeToLive, func() {
+		cache.Delete(key)
+	})
+}
+
+func (cache *timerTimeToLiveCache[K, V]) Get(key K) (V, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	value, ok := cache.data[key]
+	return value, ok
+}
+
+func (cache *timerTimeToLiveCache[K, V]) Delete(key K) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	delete(cache.data, key)
+
+	if ttlTimer, timerExists := cache.timeToLiveTimers[key]; timerExists {
+		ttlTimer.Stop()
+		delete(cache.timeToLiveTimers, key)
+	}
+}
+
+type benchmarkedCache interface {
+	Add(key string, value int)
+	Get(key string) (int, bool)
+}
+
+func benchmarkAdd(b *testing.B, cache benchmarkedCache) {
+	keys := make([]string, 10000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; b.Loop(); i++ {
+		cache.Add(keys[i % len(keys)], i)
+	}
+}
+
+func benchmarkParallelGet(b *testing.B, cache benchmarkedCache) {
+	keys := make([]string, 10000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		cache.Add(keys[i], i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Get(keys[i % len(keys)])
+		}
+	})
+}
+
+func BenchmarkAddWithTimerPerEntry(b *testing.B) {
+	benchmarkAdd(b, newTimerTimeToLiveCache[string, int](time.Minute))
+}
+
+func BenchmarkAddWithExpirationHeap(b *testing.B) {
+	cache := NewTimeToLiveCache[string, int](time.Minute)
+	defer cache.Close()
+
+	benchmarkAdd(b, cache)
+}
+
+func BenchmarkParallelGetWithTimerPerEntry(b *testing.B) {
+	benchmarkParallelGet(b, newTimerTimeToLiveCache[string, int](time.Minute))
+}
+
+func BenchmarkParallelGetWithExpirationHeap(b *testing.B) {
+	cache := NewTimeToLiveCache[string, int](time.Minute)
+	defer cache.Close()
+
+	benchmarkParallelGet(b, cache)
+}
+
+func TestAddAndDeleteShouldKeepSingleExpirationPerEntry(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute)
+	defer cache.Close()
+
+	// WHEN
+	for i := range 100 {
+		cache.Add(i % 10, i)
+	}
+	cache.Delete(0)
+	cache.Delete(1)
+
+	// THEN
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	if len(cache.expirations) != 8 || len(cache.expirationsByKey) != 8 {
+		t.Errorf("expected a single expiration for each of the 8 entries left but got %d", len(cache.expirations))
+	}
+
+	for index, scheduled := range cache.expirations {
+		if scheduled.index != index || !scheduled.expiresAt.Equal(cache.data[scheduled.key].expiresAt) {
+			t.Errorf("expiration of key '%d' does not match its entry", scheduled.key)
+		}
+	}
+}
+
+func TestOverwrittenEntryShouldExpireAtItsNewExpiration(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Millisecond * 50)
+	defer cache.Close()
+
+	cache.Add(1, 1)
+	time.Sleep(time.Millisecond * 30)
+
+	// WHEN
+	cache.Add(1, 2)
+	time.Sleep(time.Millisecond * 30)
+
+	// THEN
+	if value, isCached := cache.Get(1); !isCached || value != 2 {
+		t.Errorf("overwritten entry should not expire at its first expiration but got '%d', %t", value, isCached)
+	}
+}
+
+func TestGetOrRevalidateShouldServeStaleValueWhileRefreshing(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute).WithSoftTimeToLive(time.Nanosecond)
+	defer cache.Close()
+	const key int = 1
+
+	cache.Add(key, 1)
+	time.Sleep(time.Microsecond)
+
+	refreshed := make(chan struct{})
+	var refreshCount atomic.Int32
+
+	// WHEN
+	actual, isCached := cache.GetOrRevalidate(key, func() (int, error) {
+		refreshCount.Add(1)
+		<-refreshed
+		return 2, nil
+	})
+	cache.GetOrRevalidate(key, func() (int, error) {
+		refreshCount.Add(1)
+		return 3, nil
+	})
+	close(refreshed)
+
+	// THEN
+	if !isCached {
+		t.Fatal("stale value should be served")
+	} else if actual != 1 {
+		t.Errorf("expected stale value '1' but got '%d' instead", actual)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for value, _ := cache.Get(key); value != 2 && time.Now().Before(deadline); value, _ = cache.Get(key) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if value, _ := cache.Get(key); value != 2 {
+		t.Errorf("expected refreshed value '2' but got '%d' instead", value)
+	} else if refreshCount.Load() != 1 {
+		t.Errorf("expected a single refresh but got %d", refreshCount.Load())
+	}
+}
+
+func TestGetOrRevalidateShouldBoundConcurrentRefreshes(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute).WithSoftTimeToLive(time.Nanosecond).WithMaxConcurrentRefreshes(2)
+	defer cache.Close()
+
+	for key := range 10 {
+		cache.Add(key, key)
+	}
+	time.Sleep(time.Microsecond)
+
+	refreshed := make(chan struct{})
+	var refreshCount atomic.Int32
+
+	// WHEN
+	for key := range 10 {
+		cache.GetOrRevalidate(key, func() (int, error) {
+			refreshCount.Add(1)
+			<-refreshed
+			return key, nil
+		})
+	}
+	close(refreshed)
+
+	// THEN
+	if refreshCount.Load() > 2 {
+		t.Errorf("expected at most 2 refreshes running at the same time but got %d", refreshCount.Load())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for refreshCount.Load() != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if refreshCount.Load() != 2 {
+		t.Errorf("expected refreshes beyond the bound to be skipped but got %d", refreshCount.Load())
+	}
+}
+
+func TestGetOrRevalidateShouldNotRefreshFreshValue(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute).WithSoftTimeToLive(time.Minute)
+	defer cache.Close()
+	const key int = 1
+
+	cache.Add(key, 1)
+
+	// WHEN
+	actual, isCached := cache.GetOrRevalidate(key, func() (int, error) {
+		t.Error("fresh value should not be refreshed")
+		return 2, nil
+	})
+
+	// THEN
+	if !isCached || actual != 1 {
+		t.Errorf("expected cached value '1' but got '%d' instead", actual)
+	}
+}
+
+func TestGetOrRevalidateShouldNotServeValueAfterTimeToLive(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Millisecond).WithSoftTimeToLive(time.Nanosecond)
+	defer cache.Close()
+	const key int = 1
+
+	cache.Add(key, 1)
+	time.Sleep(time.Millisecond * 2)
+
+	// WHEN
+	_, isCached := cache.GetOrRevalidate(key, func() (int, error) {
+		return 2, nil
+	})
+
+	// THEN
+	if isCached {
+		t.Error("expired value should not be served")
+	}
+}
+
+func TestStatsShouldCountLookupsAndSweptExpirations(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Millisecond * 50)
+	defer cache.Close()
+
+	cache.Add(1, 1)
+	cache.Get(1)
+	cache.Get(2)
+	time.Sleep(time.Millisecond * 20)
+	cache.Add(2, 2)
+
+	// WHEN
+	stats := cache.Stats()
+
+	// THEN
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Size != 2 || stats.Evictions != 0 {
+		t.Errorf("expected 1 hit, 1 miss and 2 entries but got %+v", stats)
+	} else if stats.OldestEntryAge < time.Millisecond * 20 {
+		t.Errorf("expected oldest entry to be at least 20ms old but got %v", stats.OldestEntryAge)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for cache.Stats().Expirations != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if stats := cache.Stats(); stats.Expirations != 2 || stats.Size != 0 || stats.OldestEntryAge != 0 {
+		t.Errorf("expected both entries to have expired but got %+v", stats)
+	}
+}
+
+func TestGetOrLoadShouldRecordSingleLookup(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute)
+	defer cache.Close()
+
+	// WHEN
+	cache.GetOrLoad(context.Background(), 1, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	cache.GetOrLoad(context.Background(), 1, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	// THEN
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss but got %+v", stats)
+	}
+}
+
+func TestLoadShouldNotRecordLookup(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute)
+	defer cache.Close()
+
+	// WHEN
+	_, isCached := cache.Get(1)
+	value, err := cache.Load(context.Background(), 1, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	// THEN
+	if isCached || err != nil || value != 1 {
+		t.Fatalf("missing entry should be loaded but got %d, %v", value, err)
+	}
+
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 1 || stats.Size != 1 {
+		t.Errorf("expected the single miss of the lookup and a cached entry but got %+v", stats)
+	}
+}
+
+func TestKeysShouldListEntriesNotExpired(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute)
+	defer cache.Close()
+
+	cache.Add(1, 1)
+	cache.Add(2, 2)
+	cache.Delete(2)
+
+	// WHEN
+	keys := cache.Keys()
+
+	// THEN
+	if len(keys) != 1 || keys[0] != 1 {
+		t.Errorf("expected keys [1] but got %v", keys)
+	} else if formattedKeys := cache.FormattedKeys(); formattedKeys[0] != "1" {
+		t.Errorf("expected formatted key '1' but got '%s'", formattedKeys[0])
+	}
+}
+
+func TestClearShouldRemoveEveryEntry(t *testing.T) {
+	// GIVEN
+	cache := NewTimeToLiveCache[int, int](time.Minute)
+	defer cache.Close()
+
+	cache.Add(1, 1)
+	cache.Add(2, 2)
+
+	// WHEN
+	clearedCount := cache.Clear()
+
+	// THEN
+	if clearedCount != 2 {
+		t.Errorf("expected 2 cleared entries but got %d", clearedCount)
+	} else if _, isCached := cache.Get(1); isCached {
+		t.Error("no value should be cached")
+	}
+
+	cache.Add(3, 3)
+	if _, isCached := cache.Get(3); !isCached {
+		t.Error("cache should still be usable once cleared")
+	}
+}
@@ -0,0 +1,371 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"hackernews/tracing"
+)
+
+// Prefix of the environment variables configuring the server, followed by the flag name in upper snake case
+const envPrefix string = "HNPROXY_"
+const configFileFlag string = "config"
+
+// Server configuration. Each setting is read, by order of precedence, from:
+// its flag, its HNPROXY_* environment variable, the optional JSON config file, and finally its default value
+type Config struct {
+	// Interface the server listens on, every interface when empty
+	BindAddress string `json:"bindAddress"`
+	Port int `json:"port"`
+	// Port of the HTTP server exposing Prometheus metrics on the same interface, metrics being disabled when 0
+	MetricsPort int `json:"metricsPort"`
+	// Port the admin service listens on, on localhost only since it is not authenticated. The admin service is disabled when 0
+	AdminPort int `json:"adminPort"`
+	UpstreamBaseUrl string `json:"upstreamBaseUrl"`
+	ClientTimeoutSeconds uint32 `json:"clientTimeoutSeconds"`
+	LogLevel string `json:"logLevel"`
+	// Cached users are served fresh during their time to live, then stale while being refreshed until their hard time to live.
+	// Stale serving is opt-in: a hard time to live of 0 expires entries at their time to live
+	UsersCacheTtlSeconds uint32 `json:"usersCacheTtlSeconds"`
+	UsersCacheHardTtlSeconds uint32 `json:"usersCacheHardTtlSeconds"`
+	StoriesCacheTtlSeconds uint32 `json:"storiesCacheTtlSeconds"`
+	StoriesCacheHardTtlSeconds uint32 `json:"storiesCacheHardTtlSeconds"`
+	// Max number of entries kept by each cache, least recently used ones being evicted first. 0 leaves caches unbounded
+	CacheMaxEntries int `json:"cacheMaxEntries"`
+	// Time rankings of story lists are cached, requests within it reading them without reaching HackerNews API
+	RankingCacheTtlSeconds uint32 `json:"rankingCacheTtlSeconds"`
+	// Interval between background fetches of the top stories ranking, shorter than its time to live to keep it cached
+	TopStoriesPollSeconds uint32 `json:"topStoriesPollSeconds"`
+	MaxParallelFetches uint32 `json:"maxParallelFetches"`
+	WatchIntervalSeconds uint32 `json:"watchIntervalSeconds"`
+	// File caches are saved to and restored from, snapshots being disabled when empty as by default
+	SnapshotPath string `json:"snapshotPath"`
+	SnapshotIntervalSeconds uint32 `json:"snapshotIntervalSeconds"`
+	// Time in-flight requests are given to complete on shutdown, before being cut
+	ShutdownDrainSeconds uint32 `json:"shutdownDrainSeconds"`
+	// Number of consecutive failed requests to HackerNews API after which the server reports itself as not serving
+	UpstreamFailureThreshold uint32 `json:"upstreamFailureThreshold"`
+	// Interval between probes of HackerNews API while it is failing, to notice its recovery
+	UpstreamProbeIntervalSeconds uint32 `json:"upstreamProbeIntervalSeconds"`
+	// Number of attempts of requests to HackerNews API failing transiently, 1 disabling retries
+	UpstreamMaxAttempts uint32 `json:"upstreamMaxAttempts"`
+	// Wait before the first retry, doubled on each following one up to the max backoff, then randomized
+	UpstreamRetryBackoffMillis uint32 `json:"upstreamRetryBackoffMillis"`
+	UpstreamRetryMaxBackoffMillis uint32 `json:"upstreamRetryMaxBackoffMillis"`
+	// Number of consecutive failed requests to HackerNews API after which its circuit breaker opens, failing requests fast
+	CircuitBreakerThreshold uint32 `json:"circuitBreakerThreshold"`
+	// Time the circuit breaker stays open before letting a trial request through
+	CircuitBreakerOpenSeconds uint32 `json:"circuitBreakerOpenSeconds"`
+	// Exposes gRPC server reflection, for tools like grpcurl to discover services
+	Reflection bool `json:"reflection"`
+	// Where spans are exported: none, stdout or otlp
+	TracesExporter string `json:"tracesExporter"`
+	// Address of the OTLP collector spans are sent to over gRPC, with the otlp exporter
+	OtlpEndpoint string `json:"otlpEndpoint"`
+}
+
+func Default() Config {
+	return Config{
+		BindAddress: "",
+		Port: 50051,
+		MetricsPort: 9090,
+		AdminPort: 0,
+		UpstreamBaseUrl: "https://hacker-news.firebaseio.com/v0/",
+		ClientTimeoutSeconds: 20,
+		LogLevel: "info",
+		UsersCacheTtlSeconds: 40,
+		UsersCacheHardTtlSeconds: 0,
+		StoriesCacheTtlSeconds: 40,
+		StoriesCacheHardTtlSeconds: 0,
+		CacheMaxEntries: 10000,
+		RankingCacheTtlSeconds: 15,
+		TopStoriesPollSeconds: 10,
+		MaxParallelFetches: 8,
+		WatchIntervalSeconds: 30,
+		SnapshotPath: "",
+		SnapshotIntervalSeconds: 60,
+		ShutdownDrainSeconds: 10,
+		UpstreamFailureThreshold: 5,
+		UpstreamProbeIntervalSeconds: 10,
+		UpstreamMaxAttempts: 3,
+		UpstreamRetryBackoffMillis: 100,
+		UpstreamRetryMaxBackoffMillis: 2000,
+		CircuitBreakerThreshold: 5,
+		CircuitBreakerOpenSeconds: 30,
+		Reflection: false,
+		TracesExporter: tracing.NoExporter,
+		OtlpEndpoint: "localhost:4317",
+	}
+}
+
+// Reads configuration from command line arguments, environment variables looked up through lookupEnv and the config file,
+// then validates it. The config file is given by the -config flag or the HNPROXY_CONFIG environment variable
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	configPath, err := findConfigPath(args, lookupEnv)
+	if err != nil {
+		return Config{}, err
+	}
+
+	config := Default()
+
+	if configPath != "" {
+		if err := config.readFile(configPath); err != nil {
+			return Config{}, err
+		}
+	}
+
+	flags := newFlagSet(&config, io.Discard)
+
+	var envErrs []error
+	flags.VisitAll(func(setting *flag.Flag) {
+		if value, isSet := lookupEnv(envName(setting.Name)); isSet {
+			if err := setting.Value.Set(value); err != nil {
+				envErrs = append(envErrs, fmt.Errorf("invalid value '%s' for %s: %w", value, envName(setting.Name), err))
+			}
+		}
+	})
+	if err := errors.Join(envErrs...); err != nil {
+		return Config{}, err
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+// Prints flags along with their environment variables and default values
+func Usage(output io.Writer) {
+	defaults := Default()
+	flags := newFlagSet(&defaults, output)
+
+	flags.VisitAll(func(setting *flag.Flag) {
+		setting.Usage = fmt.Sprintf("%s (env %s)", setting.Usage, envName(setting.Name))
+	})
+
+	flags.PrintDefaults()
+}
+
+// Rejects configurations the server cannot run with
+func (config Config) Validate() error {
+	var errs []error
+
+	if config.Port < 1 || config.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port must be between 1 and 65535 but was %d", config.Port))
+	}
+
+	if config.MetricsPort < 0 || config.MetricsPort > 65535 {
+		errs = append(errs, fmt.Errorf("metrics port must be between 0 and 65535 but was %d", config.MetricsPort))
+	} else if config.MetricsPort == config.Port {
+		errs = append(errs, fmt.Errorf("metrics port must differ from port %d", config.Port))
+	}
+
+	if config.AdminPort < 0 || config.AdminPort > 65535 {
+		errs = append(errs, fmt.Errorf("admin port must be between 0 and 65535 but was %d", config.AdminPort))
+	} else if config.AdminPort != 0 && (config.AdminPort == config.Port || config.AdminPort == config.MetricsPort) {
+		errs = append(errs, fmt.Errorf("admin port must differ from port %d and metrics port %d", config.Port, config.MetricsPort))
+	}
+
+	if baseUrl, err := url.Parse(config.UpstreamBaseUrl); err != nil || (baseUrl.Scheme != "http" && baseUrl.Scheme != "https") || baseUrl.Host == "" {
+		errs = append(errs, fmt.Errorf("upstream base url must be an absolute http or https url but was '%s'", config.UpstreamBaseUrl))
+	}
+
+	if _, err := config.SlogLevel(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if !tracing.IsExporter(config.TracesExporter) {
+		errs = append(errs, fmt.Errorf("traces exporter must be one of none, stdout or otlp but was '%s'", config.TracesExporter))
+	}
+
+	positiveSettings := map[string]uint32{
+		"client timeout": config.ClientTimeoutSeconds,
+		"users cache time to live": config.UsersCacheTtlSeconds,
+		"stories cache time to live": config.StoriesCacheTtlSeconds,
+		"ranking cache time to live": config.RankingCacheTtlSeconds,
+		"top stories poll interval": config.TopStoriesPollSeconds,
+		"max parallel fetches": config.MaxParallelFetches,
+		"watch interval": config.WatchIntervalSeconds,
+		"snapshot interval": config.SnapshotIntervalSeconds,
+		"upstream failure threshold": config.UpstreamFailureThreshold,
+		"upstream probe interval": config.UpstreamProbeIntervalSeconds,
+		"upstream max attempts": config.UpstreamMaxAttempts,
+		"circuit breaker threshold": config.CircuitBreakerThreshold,
+		"circuit breaker open duration": config.CircuitBreakerOpenSeconds,
+	}
+	for name, value := range positiveSettings {
+		if value == 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive number", name))
+		}
+	}
+
+	if config.UsersCacheHardTtlSeconds != 0 && config.UsersCacheHardTtlSeconds < config.UsersCacheTtlSeconds {
+		errs = append(errs, errors.New("users cache hard time to live cannot be shorter than its time to live"))
+	}
+
+	if config.StoriesCacheHardTtlSeconds != 0 && config.StoriesCacheHardTtlSeconds < config.StoriesCacheTtlSeconds {
+		errs = append(errs, errors.New("stories cache hard time to live cannot be shorter than its time to live"))
+	}
+
+	if config.TopStoriesPollSeconds >= config.RankingCacheTtlSeconds {
+		errs = append(errs, errors.New("top stories poll interval must be shorter than the ranking cache time to live, for the polled ranking to stay cached"))
+	}
+
+	if config.UpstreamRetryMaxBackoffMillis < config.UpstreamRetryBackoffMillis {
+		errs = append(errs, errors.New("upstream retry max backoff cannot be shorter than its backoff"))
+	}
+
+	if config.CacheMaxEntries < 0 {
+		errs = append(errs, fmt.Errorf("cache max entries cannot be negative but was %d", config.CacheMaxEntries))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (config Config) ListenAddress() string {
+	return fmt.Sprintf("%s:%d", config.BindAddress, config.Port)
+}
+
+func (config Config) MetricsListenAddress() string {
+	return fmt.Sprintf("%s:%d", config.BindAddress, config.MetricsPort)
+}
+
+// Admin service only listens on localhost whatever the bind address, for its callers to be trusted
+func (config Config) AdminListenAddress() string {
+	return fmt.Sprintf("localhost:%d", config.AdminPort)
+}
+
+// Upstream base url, with the trailing slash HackerNews client needs to resolve its paths against it
+func (config Config) UpstreamUrl() *url.URL {
+	baseUrl, _ := url.Parse(config.UpstreamBaseUrl)
+
+	if !strings.HasSuffix(baseUrl.Path, "/") {
+		baseUrl.Path += "/"
+	}
+
+	return baseUrl
+}
+
+func (config Config) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(config.LogLevel)); err != nil {
+		return level, fmt.Errorf("log level must be one of debug, info, warn or error but was '%s'", config.LogLevel)
+	}
+
+	return level, nil
+}
+
+// Overrides settings present in the JSON file at path. Unknown settings are rejected, so that typos do not go unnoticed
+func (config *Config) readFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not read config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("could not parse config file '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+// Config file path is needed before the other settings are read, since they take precedence over it
+func findConfigPath(args []string, lookupEnv func(string) (string, bool)) (string, error) {
+	scratch := Default()
+	flags := newFlagSet(&scratch, io.Discard)
+
+	if err := flags.Parse(args); err != nil {
+		return "", err
+	}
+
+	configPath := flags.Lookup(configFileFlag).Value.String()
+	if configPath == "" {
+		configPath, _ = lookupEnv(envName(configFileFlag))
+	}
+
+	return configPath, nil
+}
+
+func newFlagSet(config *Config, output io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet("up", flag.ContinueOnError)
+	flags.SetOutput(output)
+
+	flags.String(configFileFlag, "", "Config file, only in JSON format, whose settings are overridden by environment variables and flags")
+	flags.StringVar(&config.BindAddress, "bind-address", config.BindAddress, "Interface the server listens on, every interface when empty")
+	flags.IntVar(&config.Port, "port", config.Port, "Port the server listens on")
+	flags.IntVar(&config.MetricsPort, "metrics-port", config.MetricsPort, "Port of the HTTP server exposing Prometheus metrics on /metrics, disabled when 0")
+	flags.IntVar(&config.AdminPort, "admin-port", config.AdminPort, "Port the admin service listens on, on localhost only. Disabled when 0")
+	flags.StringVar(&config.UpstreamBaseUrl, "upstream-base-url", config.UpstreamBaseUrl, "Base url of HackerNews API")
+	flags.Var(uint32Value{target: &config.ClientTimeoutSeconds}, "client-timeout-seconds", "Timeout of requests to HackerNews API")
+	flags.StringVar(&config.LogLevel, "log-level", config.LogLevel, "Min level of logs: debug, info, warn or error")
+	flags.Var(uint32Value{target: &config.UsersCacheTtlSeconds}, "users-cache-ttl-seconds", "Time users are cached fresh")
+	flags.Var(uint32Value{target: &config.UsersCacheHardTtlSeconds}, "users-cache-hard-ttl-seconds", "Time users are cached, being served stale while refreshed after their time to live. 0 to not serve them stale")
+	flags.Var(uint32Value{target: &config.StoriesCacheTtlSeconds}, "stories-cache-ttl-seconds", "Time stories are cached fresh")
+	flags.Var(uint32Value{target: &config.StoriesCacheHardTtlSeconds}, "stories-cache-hard-ttl-seconds", "Time stories are cached, being served stale while refreshed after their time to live. 0 to not serve them stale")
+	flags.IntVar(&config.CacheMaxEntries, "cache-max-entries", config.CacheMaxEntries, "Max number of entries of each cache, unbounded when 0")
+	flags.Var(uint32Value{target: &config.RankingCacheTtlSeconds}, "ranking-cache-ttl-seconds", "Time rankings of story lists are cached")
+	flags.Var(uint32Value{target: &config.TopStoriesPollSeconds}, "top-stories-poll-seconds", "Interval between background fetches of the top stories ranking")
+	flags.Var(uint32Value{target: &config.MaxParallelFetches}, "max-parallel-fetches", "Max number of stories fetched at the same time from HackerNews API")
+	flags.Var(uint32Value{target: &config.WatchIntervalSeconds}, "watch-interval-seconds", "Interval between polls of the front page for watching clients")
+	flags.StringVar(&config.SnapshotPath, "snapshot", config.SnapshotPath, "File caches are saved to on shutdown and periodically, and restored from on startup. Empty to disable")
+	flags.Var(uint32Value{target: &config.SnapshotIntervalSeconds}, "snapshot-interval-seconds", "Interval between periodic saves of caches")
+	flags.Var(uint32Value{target: &config.ShutdownDrainSeconds}, "shutdown-drain-seconds", "Time in-flight requests are given to complete on SIGINT or SIGTERM, before being cut")
+	flags.Var(uint32Value{target: &config.UpstreamFailureThreshold}, "upstream-failure-threshold", "Number of consecutive failed requests to HackerNews API after which health checks report the server as not serving")
+	flags.Var(uint32Value{target: &config.UpstreamProbeIntervalSeconds}, "upstream-probe-interval-seconds", "Interval between probes of HackerNews API while it is failing")
+	flags.Var(uint32Value{target: &config.UpstreamMaxAttempts}, "upstream-max-attempts", "Number of attempts of requests to HackerNews API failing with a server error or timeout, 1 disabling retries")
+	flags.Var(uint32Value{target: &config.UpstreamRetryBackoffMillis}, "upstream-retry-backoff-millis", "Wait before retrying a failed request to HackerNews API, doubled on each retry and randomized")
+	flags.Var(uint32Value{target: &config.UpstreamRetryMaxBackoffMillis}, "upstream-retry-max-backoff-millis", "Max wait between retries of a request to HackerNews API")
+	flags.Var(uint32Value{target: &config.CircuitBreakerThreshold}, "circuit-breaker-threshold", "Number of consecutive failed requests to HackerNews API after which requests fail fast until it recovers")
+	flags.Var(uint32Value{target: &config.CircuitBreakerOpenSeconds}, "circuit-breaker-open-seconds", "Time requests to HackerNews API fail fast before a trial request is let through")
+	flags.BoolVar(&config.Reflection, "reflection", config.Reflection, "Enables gRPC server reflection")
+	flags.StringVar(&config.TracesExporter, "traces-exporter", config.TracesExporter, "Where spans are exported: none, stdout or otlp")
+	flags.StringVar(&config.OtlpEndpoint, "otlp-endpoint", config.OtlpEndpoint, "Address of the OTLP collector spans are sent to, with the otlp traces exporter")
+
+	return flags
+}
+
+// Flag value of unsigned settings, which the flag package does not provide
+type uint32Value struct {
+	target *uint32
+}
+
+func (value uint32Value) String() string {
+	if value.target == nil {
+		return "0"
+	}
+
+	return strconv.FormatUint(uint64(*value.target), 10)
+}
+
+func (value uint32Value) Set(text string) error {
+	parsed, err := strconv.ParseUint(text, 10, 32)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a positive integer", text)
+	}
+
+	*value.target = uint32(parsed)
+	return nil
+}
+
+// Environment variable of a setting, e.g. HNPROXY_CLIENT_TIMEOUT_SECONDS for the -client-timeout-seconds flag
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
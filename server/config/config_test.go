@@ -0,0 +1,191 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func envOf(variables map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, isSet := variables[name]
+		return value, isSet
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadShouldUseDefaultsWithoutAnySetting(t *testing.T) {
+	// WHEN
+	config, err := Load(nil, envOf(nil))
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if config != Default() {
+		t.Errorf("expected default config %+v but got %+v", Default(), config)
+	}
+}
+
+func TestLoadShouldApplyFlagsOverEnvironmentOverFile(t *testing.T) {
+	// GIVEN
+	configPath := writeConfigFile(t, `{"port": 1000, "clientTimeoutSeconds": 5, "logLevel": "debug", "usersCacheTtlSeconds": 10}`)
+
+	env := envOf(map[string]string{
+		"HNPROXY_CONFIG": configPath,
+		"HNPROXY_PORT": "2000",
+		"HNPROXY_CLIENT_TIMEOUT_SECONDS": "7",
+	})
+	args := []string{"-port", "3000"}
+
+	// WHEN
+	config, err := Load(args, env)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	}
+
+	if config.Port != 3000 {
+		t.Errorf("flag should take precedence over environment, expected port 3000 but got %d", config.Port)
+	}
+
+	if config.ClientTimeoutSeconds != 7 {
+		t.Errorf("environment should take precedence over file, expected timeout 7 but got %d", config.ClientTimeoutSeconds)
+	}
+
+	if config.LogLevel != "debug" || config.UsersCacheTtlSeconds != 10 {
+		t.Errorf("file should take precedence over defaults but got %+v", config)
+	}
+
+	if config.StoriesCacheTtlSeconds != Default().StoriesCacheTtlSeconds {
+		t.Errorf("unset settings should keep their default but got %d", config.StoriesCacheTtlSeconds)
+	}
+}
+
+func TestLoadShouldPreferConfigFileFlagOverEnvironment(t *testing.T) {
+	// GIVEN
+	flagConfigPath := writeConfigFile(t, `{"port": 1000}`)
+	env := envOf(map[string]string{"HNPROXY_CONFIG": filepath.Join(t.TempDir(), "missing.json")})
+
+	// WHEN
+	config, err := Load([]string{"-config", flagConfigPath}, env)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if config.Port != 1000 {
+		t.Errorf("expected port 1000 from flag config file but got %d", config.Port)
+	}
+}
+
+func TestLoadShouldRejectInvalidSettings(t *testing.T) {
+	testCases := []struct{
+		name string
+		args []string
+		env map[string]string
+		file string
+	}{
+		{name: "port out of range", args: []string{"-port", "70000"}},
+		{name: "metrics port same as port", args: []string{"-port", "9000", "-metrics-port", "9000"}},
+		{name: "admin port same as metrics port", args: []string{"-metrics-port", "9000", "-admin-port", "9000"}},
+		{name: "unparsable environment variable", env: map[string]string{"HNPROXY_MAX_PARALLEL_FETCHES": "many"}},
+		{name: "negative unsigned flag", args: []string{"-watch-interval-seconds", "-1"}},
+		{name: "zero timeout", args: []string{"-client-timeout-seconds", "0"}},
+		{name: "hard time to live shorter than time to live", args: []string{"-users-cache-ttl-seconds", "60", "-users-cache-hard-ttl-seconds", "30"}},
+		{name: "zero upstream failure threshold", args: []string{"-upstream-failure-threshold", "0"}},
+		{name: "zero upstream attempts", args: []string{"-upstream-max-attempts", "0"}},
+		{name: "zero circuit breaker open duration", args: []string{"-circuit-breaker-open-seconds", "0"}},
+		{name: "zero ranking cache time to live", args: []string{"-ranking-cache-ttl-seconds", "0"}},
+		{name: "zero top stories poll interval", env: map[string]string{"HNPROXY_TOP_STORIES_POLL_SECONDS": "0"}},
+		{name: "top stories poll interval as long as ranking time to live", args: []string{"-ranking-cache-ttl-seconds", "10", "-top-stories-poll-seconds", "10"}},
+		{name: "max backoff shorter than backoff", args: []string{"-upstream-retry-backoff-millis", "500", "-upstream-retry-max-backoff-millis", "100"}},
+		{name: "unparsable boolean", env: map[string]string{"HNPROXY_REFLECTION": "maybe"}},
+		{name: "relative upstream url", args: []string{"-upstream-base-url", "/v0/"}},
+		{name: "unknown traces exporter", args: []string{"-traces-exporter", "jaeger"}},
+		{name: "unknown log level", env: map[string]string{"HNPROXY_LOG_LEVEL": "verbose"}},
+		{name: "unknown flag", args: []string{"-unknown"}},
+		{name: "unknown file setting", file: `{"prot": 1000}`},
+		{name: "malformed file", file: `{"port": `},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// GIVEN
+			args := testCase.args
+			if testCase.file != "" {
+				args = append(args, "-config", writeConfigFile(t, testCase.file))
+			}
+
+			// WHEN
+			_, err := Load(args, envOf(testCase.env))
+
+			// THEN
+			if err == nil {
+				t.Error("invalid configuration should be rejected")
+			}
+		})
+	}
+}
+
+func TestLoadShouldEnableReflectionFromEnvironment(t *testing.T) {
+	// WHEN
+	config, err := Load(nil, envOf(map[string]string{"HNPROXY_REFLECTION": "true"}))
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if !config.Reflection {
+		t.Error("reflection should be enabled")
+	}
+}
+
+func TestUpstreamUrlShouldEndWithSlash(t *testing.T) {
+	// GIVEN
+	config := Default()
+	config.UpstreamBaseUrl = "http://localhost:8080/v0"
+
+	// WHEN
+	upstreamUrl := config.UpstreamUrl()
+
+	// THEN
+	if upstreamUrl.String() != "http://localhost:8080/v0/" {
+		t.Errorf("expected url 'http://localhost:8080/v0/' but got '%s'", upstreamUrl)
+	}
+}
+
+func TestLoadShouldNotServeStaleEntriesByDefault(t *testing.T) {
+	// WHEN
+	config, err := Load([]string{"-users-cache-ttl-seconds", "60"}, envOf(nil))
+
+	// THEN
+	if err != nil {
+		t.Fatalf("longer time to live should be accepted without a hard time to live but got: %v", err)
+	}
+
+	if config.UsersCacheHardTtlSeconds != 0 || config.StoriesCacheHardTtlSeconds != 0 {
+		t.Errorf("hard time to live should default to 0, stale serving being opt-in, but got %+v", config)
+	}
+}
+
+func TestAdminListenAddressShouldOnlyListenOnLocalhost(t *testing.T) {
+	// GIVEN
+	config := Default()
+	config.BindAddress = "0.0.0.0"
+	config.AdminPort = 50052
+
+	// WHEN
+	address := config.AdminListenAddress()
+
+	// THEN
+	if address != "localhost:50052" {
+		t.Errorf("expected admin service to listen on 'localhost:50052' but got '%s'", address)
+	}
+}
@@ -0,0 +1,62 @@
+package frontpage
+
+import sts "hackernews/server/stories"
+
+type EventKind int
+
+const (
+	StoryEntered EventKind = iota
+	StoryLeft
+	StoryMoved
+	ScoreChanged
+)
+
+// Change observed on the front page between two polls.
+// Ranks start from 1, and Rank is 0 for stories that left the front page
+type Event struct {
+	Kind EventKind;
+	Story sts.Story;
+	Rank int;
+	PreviousRank int;
+	PreviousScore int;
+}
+
+// Computes the events turning the previous front page into the current one
+func diffFrontPages(previous []sts.Story, current []sts.Story) []Event {
+	var events []Event
+
+	previousRanks := make(map[int]int, len(previous))
+	for i, story := range previous {
+		previousRanks[story.Id] = i + 1
+	}
+
+	currentIds := make(map[int]bool, len(current))
+
+	for i, story := range current {
+		rank := i + 1
+		currentIds[story.Id] = true
+
+		previousRank, wasOnFrontPage := previousRanks[story.Id]
+		if !wasOnFrontPage {
+			events = append(events, Event{Kind: StoryEntered, Story: story, Rank: rank})
+			continue
+		}
+
+		previousScore := previous[previousRank - 1].Score
+
+		if previousRank != rank {
+			events = append(events, Event{Kind: StoryMoved, Story: story, Rank: rank, PreviousRank: previousRank, PreviousScore: previousScore})
+		}
+		if previousScore != story.Score {
+			events = append(events, Event{Kind: ScoreChanged, Story: story, Rank: rank, PreviousRank: previousRank, PreviousScore: previousScore})
+		}
+	}
+
+	for i, story := range previous {
+		if !currentIds[story.Id] {
+			events = append(events, Event{Kind: StoryLeft, Story: story, PreviousRank: i + 1, PreviousScore: story.Score})
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,182 @@
+package frontpage
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	sts "hackernews/server/stories"
+)
+
+// Number of batches of events a watcher can lag behind before being disconnected
+const watcherBufferSize = 16
+
+// Polls top stories on an interval and broadcasts front page changes to its watchers.
+// A single poller is shared by every watcher: it starts with the first one and stops with the last one
+type TopStoriesWatcher struct {
+	storiesService sts.StoriesService
+	pollInterval time.Duration
+	frontPageSize uint32
+
+	mutex sync.Mutex
+	watchers map[int]chan []Event
+	nextWatcherId int
+	frontPage []sts.Story
+	stopPolling chan struct{}
+	closed bool
+}
+
+func NewTopStoriesWatcher(storiesService sts.StoriesService, pollInterval time.Duration, frontPageSize uint32) *TopStoriesWatcher {
+	return &TopStoriesWatcher{
+		storiesService: storiesService,
+		pollInterval: pollInterval,
+		frontPageSize: frontPageSize,
+		watchers: make(map[int]chan []Event),
+	}
+}
+
+// Registers a new watcher. Batches of events are sent each time the front page changes,
+// starting with the current front page if it is already known.
+// The channel is closed if the watcher does not keep up with events, or once the watcher is closed.
+// unsubscribe must be called once done watching
+func (w *TopStoriesWatcher) Subscribe() (events <-chan []Event, unsubscribe func()) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		watcherEvents := make(chan []Event)
+		close(watcherEvents)
+		return watcherEvents, func() {}
+	}
+
+	watcherId := w.nextWatcherId
+	w.nextWatcherId++
+
+	watcherEvents := make(chan []Event, watcherBufferSize)
+	w.watchers[watcherId] = watcherEvents
+
+	if len(w.frontPage) > 0 {
+		watcherEvents <- diffFrontPages(nil, w.frontPage)
+	}
+
+	if w.stopPolling == nil {
+		w.stopPolling = make(chan struct{})
+		go w.poll(w.stopPolling)
+	}
+
+	var once sync.Once
+	unsubscribe = func() {
+		once.Do(func() {
+			w.unsubscribe(watcherId)
+		})
+	}
+
+	return watcherEvents, unsubscribe
+}
+
+func (w *TopStoriesWatcher) unsubscribe(watcherId int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if watcherEvents, exists := w.watchers[watcherId]; exists {
+		delete(w.watchers, watcherId)
+		close(watcherEvents)
+	}
+
+	if len(w.watchers) == 0 && w.stopPolling != nil {
+		close(w.stopPolling)
+		w.stopPolling = nil
+		w.frontPage = nil
+	}
+}
+
+// Stops polling and disconnects every watcher. Watchers subscribing afterwards are disconnected right away
+func (w *TopStoriesWatcher) Close() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.closed = true
+
+	for watcherId, watcherEvents := range w.watchers {
+		delete(w.watchers, watcherId)
+		close(watcherEvents)
+	}
+
+	if w.stopPolling != nil {
+		close(w.stopPolling)
+		w.stopPolling = nil
+		w.frontPage = nil
+	}
+}
+
+func (w *TopStoriesWatcher) IsClosed() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.closed
+}
+
+// Polls until stop is closed, which also aborts the fetch in progress
+func (w *TopStoriesWatcher) poll(stop chan struct{}) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		w.refreshFrontPage(ctx, stop)
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Fetches the front page and broadcasts its changes to watchers
+func (w *TopStoriesWatcher) refreshFrontPage(ctx context.Context, stop chan struct{}) {
+	stories, err := w.storiesService.GetTopStories(ctx, w.frontPageSize)
+	if ctx.Err() != nil {
+		return
+	} else if err != nil {
+		slog.Error("error while polling front page", "cause", err)
+		return
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	select {
+	case <-stop:
+		// poller stopped while fetching, its front page belongs to no watcher anymore
+		return
+	default:
+	}
+
+	events := diffFrontPages(w.frontPage, *stories)
+	w.frontPage = *stories
+
+	if len(events) == 0 {
+		return
+	}
+
+	for watcherId, watcherEvents := range w.watchers {
+		select {
+		case watcherEvents <- events:
+		default:
+			slog.Warn("front page watcher is too slow to receive events, disconnecting it", "watcher", watcherId)
+			delete(w.watchers, watcherId)
+			close(watcherEvents)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package frontpage
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sts "hackernews/server/stories"
+)
+
+type MockStoriesService struct {
+	sts.StoriesService
+	MockedGetTopStories func(maxStoryCount uint32) (*[]sts.Story, error)
+}
+
+func (m MockStoriesService) GetTopStories(_ context.Context, maxStoryCount uint32) (*[]sts.Story, error) {
+	return m.MockedGetTopStories(maxStoryCount)
+}
+
+func TestDiffFrontPagesShouldDetectEveryChange(t *testing.T) {
+	// GIVEN
+	previous := []sts.Story{{Id: 1, Score: 10}, {Id: 2, Score: 20}, {Id: 3, Score: 30}}
+	current := []sts.Story{{Id: 2, Score: 25}, {Id: 1, Score: 10}, {Id: 4, Score: 1}}
+
+	// WHEN
+	events := diffFrontPages(previous, current)
+
+	// THEN
+	expectedEvents := []Event{
+		{Kind: StoryMoved, Story: current[0], Rank: 1, PreviousRank: 2, PreviousScore: 20},
+		{Kind: ScoreChanged, Story: current[0], Rank: 1, PreviousRank: 2, PreviousScore: 20},
+		{Kind: StoryMoved, Story: current[1], Rank: 2, PreviousRank: 1, PreviousScore: 10},
+		{Kind: StoryEntered, Story: current[2], Rank: 3},
+		{Kind: StoryLeft, Story: previous[2], PreviousRank: 3, PreviousScore: 30},
+	}
+
+	if len(events) != len(expectedEvents) {
+		t.Fatalf("expected %d events but got %d: %+v", len(expectedEvents), len(events), events)
+	}
+
+	for i, expected := range expectedEvents {
+		actual := events[i]
+
+		if actual.Kind != expected.Kind || actual.Story.Id != expected.Story.Id || actual.Rank != expected.Rank ||
+			actual.PreviousRank != expected.PreviousRank || actual.PreviousScore != expected.PreviousScore {
+			t.Errorf("expected event %+v but got %+v", expected, actual)
+		}
+	}
+}
+
+func TestDiffFrontPagesShouldNotReportUnchangedStories(t *testing.T) {
+	// GIVEN
+	frontPage := []sts.Story{{Id: 1, Score: 10}, {Id: 2, Score: 20}}
+
+	// WHEN
+	events := diffFrontPages(frontPage, frontPage)
+
+	// THEN
+	if len(events) != 0 {
+		t.Errorf("no event expected but got %+v", events)
+	}
+}
+
+func TestWatchersShouldShareSinglePoller(t *testing.T) {
+	// GIVEN
+	var pollCount atomic.Int32
+
+	storiesService := MockStoriesService{}
+	storiesService.MockedGetTopStories = func(maxStoryCount uint32) (*[]sts.Story, error) {
+		pollCount.Add(1)
+		return &[]sts.Story{{Id: 1}}, nil
+	}
+
+	watcher := NewTopStoriesWatcher(storiesService, time.Hour, 30)
+
+	// WHEN
+	firstEvents, unsubscribeFirst := watcher.Subscribe()
+	<-firstEvents
+	secondEvents, unsubscribeSecond := watcher.Subscribe()
+	<-secondEvents
+
+	// THEN
+	if pollCount.Load() != 1 {
+		t.Errorf("expected a single poll shared by watchers but got %d", pollCount.Load())
+	}
+
+	unsubscribeFirst()
+	unsubscribeSecond()
+
+	if watcher.stopPolling != nil {
+		t.Error("poller should stop once every watcher left")
+	}
+}
+
+func TestSubscribeShouldSendCurrentFrontPageAsEnteredStories(t *testing.T) {
+	// GIVEN
+	storiesService := MockStoriesService{}
+	storiesService.MockedGetTopStories = func(maxStoryCount uint32) (*[]sts.Story, error) {
+		return &[]sts.Story{{Id: 1}, {Id: 2}}, nil
+	}
+
+	watcher := NewTopStoriesWatcher(storiesService, time.Hour, 30)
+	firstEvents, unsubscribeFirst := watcher.Subscribe()
+	defer unsubscribeFirst()
+	<-firstEvents
+
+	// WHEN
+	secondEvents, unsubscribeSecond := watcher.Subscribe()
+	defer unsubscribeSecond()
+
+	// THEN
+	select {
+	case events := <-secondEvents:
+		if len(events) != 2 || events[0].Kind != StoryEntered || events[1].Kind != StoryEntered {
+			t.Errorf("expected current front page as entered stories but got %+v", events)
+		}
+	case <-time.After(time.Second):
+		t.Error("current front page should be sent to new watcher")
+	}
+}
+
+func TestCloseShouldDisconnectWatchersAndStopPolling(t *testing.T) {
+	// GIVEN
+	storiesService := MockStoriesService{}
+	storiesService.MockedGetTopStories = func(maxStoryCount uint32) (*[]sts.Story, error) {
+		return &[]sts.Story{{Id: 1}}, nil
+	}
+
+	watcher := NewTopStoriesWatcher(storiesService, time.Hour, 30)
+	events, unsubscribe := watcher.Subscribe()
+	defer unsubscribe()
+	<-events
+
+	// WHEN
+	watcher.Close()
+
+	// THEN
+	if _, isWatching := <-events; isWatching {
+		t.Error("watcher should have been disconnected")
+	}
+
+	if watcher.stopPolling != nil {
+		t.Error("poller should stop once closed")
+	}
+
+	lateEvents, unsubscribeLate := watcher.Subscribe()
+	defer unsubscribeLate()
+	if _, isWatching := <-lateEvents; isWatching {
+		t.Error("watchers subscribing once closed should be disconnected right away")
+	}
+}
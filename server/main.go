@@ -1,57 +1,433 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
 	grpcHn "hackernews/generated"
 
 	"hackernews/server/cache"
+	"hackernews/server/config"
+	"hackernews/server/frontpage"
+	"hackernews/server/metrics"
 	proxyServer "hackernews/server/server"
 	sts "hackernews/server/stories"
+	"hackernews/server/upstream"
 	us "hackernews/server/users"
+	"hackernews/tracing"
 
-	hn "github.com/peterhellberg/hn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-const port int = 50051
-const defaultCacheTtlSeconds uint32 = 40
-const defaultClientTimeoutSeconds uint32 = 20
+const frontPageSize uint32 = 30
+const maxStoriesPerRequest uint32 = 500
 
 func main() {
     if len(os.Args) == 1 || os.Args[1] != "up" {
-        fmt.Println("Usage : go run server/main.go up")
+        fmt.Println("Usage : go run server/main.go up [flags]")
+        config.Usage(os.Stdout)
         return
     }
 
-    listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	serverConfig, err := config.Load(os.Args[2:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		config.Usage(os.Stdout)
+		return
+	} else if err != nil {
+		fmt.Printf("Invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+
+	logLevel, _ := serverConfig.SlogLevel()
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
+	slog.Debug("configuration loaded", "config", serverConfig)
+
+	shutdownTracing, err := tracing.Setup(serverConfig.TracesExporter, serverConfig.OtlpEndpoint, "hackernews-proxy")
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+	defer flushTraces(shutdownTracing)
+
+    listener, err := net.Listen("tcp", serverConfig.ListenAddress())
     if err != nil {
         log.Fatalf("failed to listen: %v", err)
     }
 
-    s := grpc.NewServer()
+	var metricsListener net.Listener
+	if serverConfig.MetricsPort != 0 {
+		metricsListener, err = net.Listen("tcp", serverConfig.MetricsListenAddress())
+		if err != nil {
+			log.Fatalf("failed to listen for metrics: %v", err)
+		}
+	}
+
+	var adminListener net.Listener
+	if serverConfig.AdminPort != 0 {
+		adminListener, err = net.Listen("tcp", serverConfig.AdminListenAddress())
+		if err != nil {
+			log.Fatalf("failed to listen for admin service: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := serve(ctx, listener, metricsListener, adminListener, serverConfig, http.DefaultTransport); err != nil {
+		log.Fatalf("failed to serve: %v", err)
+	}
+}
+
+// Serves requests on listener until ctx is done, then shuts down: watchers are disconnected, in-flight requests are given
+// the configured drain time to complete before being cut, and caches are saved to their snapshot before being released.
+// Prometheus metrics are served on metricsListener, and the admin service on adminListener, unless they are nil.
+// Requests to HackerNews API are sent through upstreamTransport
+func serve(ctx context.Context, listener net.Listener, metricsListener net.Listener, adminListener net.Listener, serverConfig config.Config, upstreamTransport http.RoundTripper) error {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	rpcMetrics := metrics.NewRpcMetrics(registry)
+
+	// background refreshes of stale entries are bounded like story fetches, so that a large list does not flood HackerNews API
+	maxRefreshes := int(serverConfig.MaxParallelFetches)
+	userCache := newCache[string, *us.User](seconds(serverConfig.UsersCacheTtlSeconds), seconds(serverConfig.UsersCacheHardTtlSeconds), serverConfig.CacheMaxEntries, maxRefreshes)
+	storiesCache := newCache[int, *sts.Story](seconds(serverConfig.StoriesCacheTtlSeconds), seconds(serverConfig.StoriesCacheHardTtlSeconds), serverConfig.CacheMaxEntries, maxRefreshes)
+	// rankings are not snapshotted, they would be outdated by the time the server restarts
+	rankingsCache := cache.NewTimeToLiveCache[sts.StoryList, *sts.Ranking](seconds(serverConfig.RankingCacheTtlSeconds))
+	defer userCache.Close()
+	defer storiesCache.Close()
+	defer rankingsCache.Close()
+
+	snapshots := cache.NewSnapshotStore(serverConfig.SnapshotPath)
+	cache.RegisterSnapshot(snapshots, "users", userCache)
+	cache.RegisterSnapshot(snapshots, "stories", storiesCache)
 
-	timeToLiveDuration := time.Second * time.Duration(defaultCacheTtlSeconds)
-	userCache := cache.NewTimeToLiveCache[string, *us.User](timeToLiveDuration)
-	storiesCache := cache.NewTimeToLiveCache[int, *sts.Story](timeToLiveDuration)
+	if serverConfig.SnapshotPath != "" {
+		restoredCount, err := snapshots.Load()
+		if err != nil {
+			slog.Warn("could not restore caches from snapshot, starting with empty caches", "cause", err)
+		} else {
+			slog.Info("caches restored from snapshot", "entries", restoredCount, "path", serverConfig.SnapshotPath)
+		}
 
-	clientTimeout := time.Duration(defaultClientTimeoutSeconds) * time.Second
-	hnClient := hn.NewClient(&http.Client{Timeout: clientTimeout})
+		stopSnapshotting := saveSnapshotsPeriodically(snapshots, seconds(serverConfig.SnapshotIntervalSeconds))
+		defer stopSnapshotting()
+	}
+
+	healthServer := health.NewServer()
+	hnStatus := newHnServingStatus(healthServer)
+	upstreamHealth := upstream.NewHealthMonitor(
+		serverConfig.UpstreamFailureThreshold,
+		probeUpstream(newHttpClient(serverConfig, upstreamTransport), serverConfig.UpstreamUrl()),
+		seconds(serverConfig.UpstreamProbeIntervalSeconds),
+		func(healthy bool) {
+			if healthy {
+				slog.Info("HackerNews API recovered")
+			} else {
+				slog.Warn("HackerNews API keeps failing, not serving until it recovers")
+			}
+			hnStatus.setUpstreamHealthy(healthy)
+		},
+	)
+	defer upstreamHealth.Close()
+
+	circuitBreaker := upstream.NewCircuitBreaker(
+		serverConfig.CircuitBreakerThreshold,
+		seconds(serverConfig.CircuitBreakerOpenSeconds),
+		func(state upstream.CircuitState) {
+			slog.Info("HackerNews API circuit breaker changed state", "state", state.String())
+			hnStatus.setCircuitOpen(state == upstream.CircuitOpen)
+		},
+	)
+	defer circuitBreaker.Close()
+	registry.MustRegister(metrics.NewCircuitBreakerCollector(circuitBreaker))
+
+	// an open circuit fails requests before they are retried, while retries of a request count as a single failure
+	upstreamClient := upstream.NewCircuitBreakingClient(
+		upstream.NewRetryingClient(
+			upstream.NewClient(newHttpClient(serverConfig, metrics.NewUpstreamTransport(upstream.NewMonitoredTransport(upstreamTransport, upstreamHealth), registry)), serverConfig.UpstreamUrl()),
+			upstream.RetryPolicy{
+				MaxAttempts: serverConfig.UpstreamMaxAttempts,
+				InitialBackoff: time.Duration(serverConfig.UpstreamRetryBackoffMillis) * time.Millisecond,
+				MaxBackoff: time.Duration(serverConfig.UpstreamRetryMaxBackoffMillis) * time.Millisecond,
+			},
+		),
+		circuitBreaker,
+	)
+
+	storiesService := sts.NewHackernewsStoriesProxy(upstreamClient, storiesCache, rankingsCache, serverConfig.MaxParallelFetches)
+	stopPolling := refreshTopStoriesPeriodically(storiesService, seconds(serverConfig.TopStoriesPollSeconds))
+	defer stopPolling()
+
+	topStoriesWatcher := frontpage.NewTopStoriesWatcher(storiesService, seconds(serverConfig.WatchIntervalSeconds), frontPageSize)
+	defer topStoriesWatcher.Close()
 
 	hnServer := proxyServer.NewHnProxyServer(
-		sts.NewHackernewsStoriesProxy(*hnClient, storiesCache),
-		us.NewHackernewsUserProxy(*hnClient, userCache),
+		storiesService,
+		us.NewHackernewsUserProxy(upstreamClient, userCache),
+		topStoriesWatcher,
+		maxStoriesPerRequest,
 	)
 
+	adminServer := proxyServer.NewHnAdminServer(userCache, storiesCache, rankingsCache)
+	registry.MustRegister(metrics.NewCacheCollector(adminServer.Caches))
+
+    s := newGrpcServer(rpcMetrics)
     grpcHn.RegisterHnServiceServer(s, &hnServer)
-    log.Printf("server listening at %v", listener.Addr())
-    if err := s.Serve(listener); err != nil {
-        log.Fatalf("failed to serve: %v", err)
-    }
+	healthpb.RegisterHealthServer(s, healthServer)
+
+	if serverConfig.Reflection {
+		reflection.Register(s)
+	}
+
+	if metricsListener != nil {
+		stopMetrics := serveMetrics(metricsListener, registry)
+		defer stopMetrics()
+	}
+
+	// the admin service is not authenticated, so it is kept off the public listener
+	if adminListener != nil {
+		adminGrpcServer := newGrpcServer(rpcMetrics)
+		grpcHn.RegisterHnAdminServiceServer(adminGrpcServer, &adminServer)
+
+		go func() {
+			slog.Info("admin service listening", "address", adminListener.Addr())
+			if err := adminGrpcServer.Serve(adminListener); err != nil {
+				slog.Error("admin server stopped", "cause", err)
+			}
+		}()
+		defer adminGrpcServer.Stop()
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		slog.Info("server listening", "address", listener.Addr())
+		served <- s.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down server")
+	// load balancers stop sending new requests while in-flight ones drain
+	healthServer.Shutdown()
+	// watch streams never complete by themselves, they would hold the graceful stop until its deadline
+	topStoriesWatcher.Close()
+	stopGracefully(s, seconds(serverConfig.ShutdownDrainSeconds))
+
+	return <-served
+}
+
+func newGrpcServer(rpcMetrics *metrics.RpcMetrics) *grpc.Server {
+	return grpc.NewServer(
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor(), rpcMetrics.UnaryInterceptor()),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor(), rpcMetrics.StreamInterceptor()),
+	)
+}
+
+// Lets in-flight requests complete, then stops the server. Connections of requests still running after drainTimeout are closed
+func stopGracefully(s *grpc.Server, drainTimeout time.Duration) {
+	drained := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(drainTimeout):
+		slog.Warn("in-flight requests did not complete in time, cutting them", "drainTimeout", drainTimeout)
+		// connections are closed right away, but Stop only returns once the handlers of cut requests have returned
+		s.Stop()
+	}
+}
+
+// Serves metrics of registry on /metrics until the returned function is called
+func serveMetrics(listener net.Listener, registry *prometheus.Registry) func() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}))
+	metricsServer := &http.Server{Handler: mux}
+
+	go func() {
+		slog.Info("metrics listening", "address", listener.Addr())
+		if err := metricsServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server stopped", "cause", err)
+		}
+	}()
+
+	return func() {
+		metricsServer.Close()
+	}
+}
+
+// Exports spans not sent yet, giving up after a few seconds so that an unreachable collector does not hold the exit
+func flushTraces(shutdownTracing func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5 * time.Second)
+	defer cancel()
+
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Warn("could not export remaining spans", "cause", err)
+	}
+}
+
+// Saves snapshots every interval until the returned function is called, which saves them one last time
+func saveSnapshotsPeriodically(snapshots *cache.SnapshotStore, interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+
+	save := func() {
+		if err := snapshots.Save(); err != nil {
+			slog.Error("could not save caches snapshot", "cause", err)
+		}
+	}
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-ticker.C:
+				save()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(stop)
+		<-stopped
+		save()
+	}
+}
+
+// Fetches the top stories ranking every interval until the returned function is called, so that requests read it from cache
+// instead of each fetching it from HackerNews API. A poll in flight when stopping is aborted
+func refreshTopStoriesPeriodically(storiesService sts.StoriesService, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := time.NewTicker(interval)
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := storiesService.RefreshRanking(ctx, sts.TopStories); err != nil && ctx.Err() == nil {
+					slog.Warn("could not refresh top stories ranking", "cause", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		cancel()
+		<-stopped
+	}
+}
+
+func newHttpClient(serverConfig config.Config, transport http.RoundTripper) *http.Client {
+	return &http.Client{Timeout: seconds(serverConfig.ClientTimeoutSeconds), Transport: transport}
+}
+
+// Checks HackerNews API answers its lightest request
+func probeUpstream(httpClient *http.Client, baseUrl *url.URL) func() error {
+	return func() error {
+		response, err := httpClient.Get(baseUrl.JoinPath("maxitem.json").String())
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+
+		if response.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("HackerNews API answered %s", response.Status)
+		}
+
+		return nil
+	}
+}
+
+// Serving status of HackerNews service, and of the server as a whole: serving while HackerNews API is healthy
+// and its circuit breaker is not open
+type hnServingStatus struct {
+	mutex sync.Mutex
+	healthServer *health.Server
+	upstreamHealthy bool
+	circuitOpen bool
+}
+
+func newHnServingStatus(healthServer *health.Server) *hnServingStatus {
+	hnStatus := &hnServingStatus{healthServer: healthServer, upstreamHealthy: true}
+	hnStatus.update(func() {})
+
+	return hnStatus
+}
+
+func (hnStatus *hnServingStatus) setUpstreamHealthy(healthy bool) {
+	hnStatus.update(func() { hnStatus.upstreamHealthy = healthy })
+}
+
+func (hnStatus *hnServingStatus) setCircuitOpen(open bool) {
+	hnStatus.update(func() { hnStatus.circuitOpen = open })
+}
+
+func (hnStatus *hnServingStatus) update(change func()) {
+	hnStatus.mutex.Lock()
+	defer hnStatus.mutex.Unlock()
+
+	change()
+
+	if hnStatus.upstreamHealthy && !hnStatus.circuitOpen {
+		setHnServingStatus(hnStatus.healthServer, healthpb.HealthCheckResponse_SERVING)
+	} else {
+		setHnServingStatus(hnStatus.healthServer, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+func setHnServingStatus(healthServer *health.Server, servingStatus healthpb.HealthCheckResponse_ServingStatus) {
+	healthServer.SetServingStatus("", servingStatus)
+	healthServer.SetServingStatus(grpcHn.HnService_ServiceDesc.ServiceName, servingStatus)
+}
+
+func seconds(count uint32) time.Duration {
+	return time.Duration(count) * time.Second
+}
+
+// Entries are served fresh until softTimeToLive, then stale while being refreshed until hardTimeToLive.
+// A hard time to live shorter than the soft one, such as the default 0, expires entries at softTimeToLive.
+// At most maxRefreshes stale entries are refreshed at the same time
+func newCache[K comparable, V any](softTimeToLive time.Duration, hardTimeToLive time.Duration, maxEntries int, maxRefreshes int) cache.Cache[K, V] {
+	hardTimeToLive = max(hardTimeToLive, softTimeToLive)
+
+	if maxEntries > 0 {
+		return cache.NewLruTimeToLiveCache[K, V](hardTimeToLive, maxEntries).WithSoftTimeToLive(softTimeToLive).WithMaxConcurrentRefreshes(maxRefreshes)
+	}
+
+	return cache.NewTimeToLiveCache[K, V](hardTimeToLive).WithSoftTimeToLive(softTimeToLive).WithMaxConcurrentRefreshes(maxRefreshes)
 }
@@ -0,0 +1,528 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	grpcHn "hackernews/generated"
+
+	"hackernews/server/cache"
+	"hackernews/server/config"
+	sts "hackernews/server/stories"
+)
+
+// Fake HackerNews API whose single top story is only answered once released. Returns its base url
+func newFakeHn(t *testing.T, storyRequested chan<- struct{}, releaseStory <-chan struct{}) string {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/topstories.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[1]")
+	})
+	mux.HandleFunc("/v0/item/1.json", func(w http.ResponseWriter, r *http.Request) {
+		storyRequested <- struct{}{}
+		<-releaseStory
+		fmt.Fprint(w, `{"id": 1, "type": "story", "title": "Show HN: graceful shutdown"}`)
+	})
+
+	upstream := httptest.NewServer(mux)
+	t.Cleanup(upstream.Close)
+
+	return upstream.URL + "/v0/"
+}
+
+func testConfig(t *testing.T, upstreamBaseUrl string, drainSeconds uint32) config.Config {
+	serverConfig := config.Default()
+	serverConfig.UpstreamBaseUrl = upstreamBaseUrl
+	serverConfig.SnapshotPath = filepath.Join(t.TempDir(), "cache.snapshot")
+	serverConfig.WatchIntervalSeconds = 3600
+	serverConfig.ShutdownDrainSeconds = drainSeconds
+
+	return serverConfig
+}
+
+// Serves in background until the returned context is cancelled. serve result is sent to the returned channel.
+// Metrics are only served when metricsListener is not nil, while the admin service is not served
+func startServer(t *testing.T, serverConfig config.Config, metricsListener net.Listener) (context.CancelFunc, <-chan error, *grpc.ClientConn) {
+	listener := bufconn.Listen(1024 * 1024)
+	ctx, shutdown := context.WithCancel(context.Background())
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, listener, metricsListener, nil, serverConfig, http.DefaultTransport)
+	}()
+
+	return shutdown, served, dial(t, listener)
+}
+
+// Same as startServer, the admin service being served on the connection returned last
+func startServerWithAdmin(t *testing.T, serverConfig config.Config) (context.CancelFunc, <-chan error, *grpc.ClientConn, *grpc.ClientConn) {
+	listener := bufconn.Listen(1024 * 1024)
+	adminListener := bufconn.Listen(1024 * 1024)
+	ctx, shutdown := context.WithCancel(context.Background())
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, listener, nil, adminListener, serverConfig, http.DefaultTransport)
+	}()
+
+	return shutdown, served, dial(t, listener), dial(t, adminListener)
+}
+
+func dial(t *testing.T, listener *bufconn.Listener) *grpc.ClientConn {
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func waitServed(t *testing.T, served <-chan error, timeout time.Duration) {
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("server should stop without error but got: %v", err)
+		}
+	case <-time.After(timeout):
+		t.Fatalf("server did not stop within %v", timeout)
+	}
+}
+
+func TestServeShouldDrainInFlightRequestsAndSaveCachesOnShutdown(t *testing.T) {
+	// GIVEN
+	storyRequested := make(chan struct{}, 1)
+	releaseStory := make(chan struct{})
+	serverConfig := testConfig(t, newFakeHn(t, storyRequested, releaseStory), 5)
+
+	shutdown, served, conn := startServer(t, serverConfig, nil)
+	client := grpcHn.NewHnServiceClient(conn)
+
+	rpcResult := make(chan error, 1)
+	go func() {
+		_, err := client.GetTopStories(context.Background(), &grpcHn.TopStoriesRequest{StoryNumber: 1})
+		rpcResult <- err
+	}()
+	<-storyRequested
+
+	// WHEN
+	shutdown()
+	time.Sleep(time.Millisecond * 50)
+	close(releaseStory)
+
+	// THEN
+	if err := <-rpcResult; err != nil {
+		t.Errorf("in-flight request should complete but got: %v", err)
+	}
+
+	waitServed(t, served, time.Second * 2)
+
+	restoredCache := cache.NewLruTimeToLiveCache[int, *sts.Story](time.Minute, 10)
+	store := cache.NewSnapshotStore(serverConfig.SnapshotPath)
+	cache.RegisterSnapshot(store, "stories", restoredCache)
+
+	if restoredCount, err := store.Load(); err != nil || restoredCount != 1 {
+		t.Errorf("fetched story should have been saved on shutdown, but restored %d entries with error: %v", restoredCount, err)
+	}
+}
+
+func TestServeShouldCutRequestsStillRunningAfterDrainDeadline(t *testing.T) {
+	// GIVEN
+	storyRequested := make(chan struct{}, 1)
+	releaseStory := make(chan struct{})
+
+	shutdown, served, conn := startServer(t, testConfig(t, newFakeHn(t, storyRequested, releaseStory), 0), nil)
+	client := grpcHn.NewHnServiceClient(conn)
+
+	rpcResult := make(chan error, 1)
+	go func() {
+		_, err := client.GetTopStories(context.Background(), &grpcHn.TopStoriesRequest{StoryNumber: 1})
+		rpcResult <- err
+	}()
+	<-storyRequested
+
+	// WHEN
+	shutdown()
+
+	// THEN
+	select {
+	case err := <-rpcResult:
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("request running after drain deadline should be cut but got: %v", err)
+		}
+	case <-time.After(time.Second * 2):
+		t.Error("request running after drain deadline should be cut right away")
+	}
+
+	// handler of the cut request still has to return for the server to stop
+	close(releaseStory)
+	waitServed(t, served, time.Second * 2)
+}
+
+func TestServeShouldEndWatchStreamsOnShutdown(t *testing.T) {
+	// GIVEN
+	storyRequested := make(chan struct{}, 1)
+	releaseStory := make(chan struct{})
+	close(releaseStory)
+
+	shutdown, served, conn := startServer(t, testConfig(t, newFakeHn(t, storyRequested, releaseStory), 5), nil)
+	client := grpcHn.NewHnServiceClient(conn)
+
+	stream, err := client.WatchTopStories(context.Background(), &grpcHn.WatchTopStoriesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("current front page should be received but got: %v", err)
+	}
+
+	// WHEN
+	shutdown()
+
+	// THEN
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("watch stream should end as unavailable but got: %v", err)
+	}
+
+	waitServed(t, served, time.Second * 2)
+}
+
+func TestServeShouldReportNotServingWhileHackernewsApiFails(t *testing.T) {
+	// GIVEN
+	var upstreamFailing atomic.Bool
+	upstreamFailing.Store(true)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if upstreamFailing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"id": 1, "type": "story", "title": "Ask HN: is it up?"}`)
+	}))
+	defer upstream.Close()
+
+	serverConfig := testConfig(t, upstream.URL + "/v0/", 5)
+	serverConfig.UpstreamFailureThreshold = 2
+
+	shutdown, served, conn := startServer(t, serverConfig, nil)
+	client := grpcHn.NewHnServiceClient(conn)
+	healthClient := healthpb.NewHealthClient(conn)
+
+	checkHnService := func() healthpb.HealthCheckResponse_ServingStatus {
+		response, err := healthClient.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "hackernews.HnService"})
+		if err != nil {
+			t.Fatalf("health check should succeed but got: %v", err)
+		}
+		return response.Status
+	}
+
+	if checkHnService() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatal("server should be serving on startup")
+	}
+
+	// WHEN
+	for range 2 {
+		if _, err := client.GetItem(context.Background(), &grpcHn.ItemRequest{Id: 1}); err == nil {
+			t.Fatal("item fetch should fail while HackerNews API fails")
+		}
+	}
+
+	// THEN
+	if status := checkHnService(); status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("server should not be serving once failures reach threshold but was %v", status)
+	}
+
+	// WHEN
+	upstreamFailing.Store(false)
+	if _, err := client.GetItem(context.Background(), &grpcHn.ItemRequest{Id: 1}); err != nil {
+		t.Fatalf("item fetch should succeed once HackerNews API recovered but got: %v", err)
+	}
+
+	// THEN
+	if status := checkHnService(); status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("server should be serving again once HackerNews API recovered but was %v", status)
+	}
+
+	shutdown()
+	waitServed(t, served, time.Second * 2)
+}
+
+func TestServeShouldFailFastWhileCircuitBreakerIsOpen(t *testing.T) {
+	// GIVEN
+	var upstreamFailing atomic.Bool
+	upstreamFailing.Store(true)
+	var upstreamRequests atomic.Int32
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamRequests.Add(1)
+		if upstreamFailing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"id": 1, "type": "story", "title": "Ask HN: is it up?"}`)
+	}))
+	defer upstream.Close()
+
+	serverConfig := testConfig(t, upstream.URL + "/v0/", 5)
+	serverConfig.UpstreamFailureThreshold = 100
+	serverConfig.UpstreamMaxAttempts = 1
+	serverConfig.CircuitBreakerThreshold = 1
+	serverConfig.CircuitBreakerOpenSeconds = 1
+
+	shutdown, served, conn := startServer(t, serverConfig, nil)
+	client := grpcHn.NewHnServiceClient(conn)
+	healthClient := healthpb.NewHealthClient(conn)
+
+	if _, err := client.GetItem(context.Background(), &grpcHn.ItemRequest{Id: 1}); status.Code(err) != codes.Internal {
+		t.Fatalf("item fetch should fail while HackerNews API fails but got: %v", err)
+	}
+
+	// WHEN
+	_, err := client.GetItem(context.Background(), &grpcHn.ItemRequest{Id: 1})
+
+	// THEN
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("item fetch should fail fast as unavailable while circuit is open but got: %v", err)
+	}
+
+	if upstreamRequests.Load() != 1 {
+		t.Errorf("HackerNews API should not be requested while circuit is open, but was %d times", upstreamRequests.Load())
+	}
+
+	health, err := healthClient.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "hackernews.HnService"})
+	if err != nil || health.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("server should not be serving while circuit is open but got %v, %v", health, err)
+	}
+
+	// WHEN
+	upstreamFailing.Store(false)
+	time.Sleep(time.Millisecond * 1200)
+	_, err = client.GetItem(context.Background(), &grpcHn.ItemRequest{Id: 1})
+
+	// THEN
+	if err != nil {
+		t.Errorf("trial request should succeed once HackerNews API recovered but got: %v", err)
+	}
+
+	health, err = healthClient.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "hackernews.HnService"})
+	if err != nil || health.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("server should be serving again once circuit closed but got %v, %v", health, err)
+	}
+
+	shutdown()
+	waitServed(t, served, time.Second * 2)
+}
+
+func TestServeShouldFlagStoriesServedWhileHackernewsApiFailsAsStale(t *testing.T) {
+	// GIVEN
+	var upstreamFailing atomic.Bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/topstories.json", func(w http.ResponseWriter, r *http.Request) {
+		if upstreamFailing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, "[1]")
+	})
+	mux.HandleFunc("/v0/item/1.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1, "type": "story", "title": "Ask HN: is it up?"}`)
+	})
+	upstream := httptest.NewServer(mux)
+	defer upstream.Close()
+
+	serverConfig := testConfig(t, upstream.URL + "/v0/", 5)
+	serverConfig.UpstreamMaxAttempts = 1
+
+	shutdown, served, conn, adminConn := startServerWithAdmin(t, serverConfig)
+	client := grpcHn.NewHnServiceClient(conn)
+
+	if _, err := client.GetTopStories(context.Background(), &grpcHn.TopStoriesRequest{StoryNumber: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// WHEN
+	upstreamFailing.Store(true)
+	// ranking expired from cache
+	adminClient := grpcHn.NewHnAdminServiceClient(adminConn)
+	if _, err := adminClient.PurgeCache(context.Background(), &grpcHn.PurgeCacheRequest{Name: "rankings"}); err != nil {
+		t.Fatal(err)
+	}
+	topStories, err := client.GetTopStories(context.Background(), &grpcHn.TopStoriesRequest{StoryNumber: 1})
+
+	stream, streamErr := client.StreamTopStories(context.Background(), &grpcHn.TopStoriesRequest{StoryNumber: 1})
+	if streamErr != nil {
+		t.Fatal(streamErr)
+	}
+	var streamedCount int
+	for _, streamErr = stream.Recv(); streamErr == nil; _, streamErr = stream.Recv() {
+		streamedCount++
+	}
+
+	// THEN
+	if err != nil || len(topStories.GetStories()) != 1 || !topStories.GetStale() {
+		t.Errorf("last top stories fetched should be served as stale but got %v, %v", topStories, err)
+	}
+
+	if streamErr != io.EOF || streamedCount != 1 {
+		t.Errorf("last top stories fetched should be streamed but got %d stories and %v", streamedCount, streamErr)
+	}
+
+	if staleAge := stream.Trailer().Get("hnproxy-stale-age-seconds"); len(staleAge) != 1 {
+		t.Errorf("stream of stale stories should tell their age in trailer but got %v", stream.Trailer())
+	}
+
+	if fetchedAt := stream.Trailer().Get("hnproxy-ranking-fetched-at"); len(fetchedAt) != 1 {
+		t.Errorf("stream should tell when the ranking of its stories was fetched in trailer but got %v", stream.Trailer())
+	}
+
+	shutdown()
+	waitServed(t, served, time.Second * 2)
+}
+
+func TestServeShouldPollTopStoriesRankingSoThatRequestsReadItFromCache(t *testing.T) {
+	// GIVEN
+	var rankingRequests atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/topstories.json", func(w http.ResponseWriter, r *http.Request) {
+		rankingRequests.Add(1)
+		fmt.Fprint(w, "[1]")
+	})
+	mux.HandleFunc("/v0/item/1.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1, "type": "story", "title": "Ask HN: is it up?"}`)
+	})
+	upstream := httptest.NewServer(mux)
+	defer upstream.Close()
+
+	serverConfig := testConfig(t, upstream.URL + "/v0/", 5)
+	serverConfig.TopStoriesPollSeconds = 1
+
+	shutdown, served, conn := startServer(t, serverConfig, nil)
+	client := grpcHn.NewHnServiceClient(conn)
+
+	// WHEN
+	time.Sleep(time.Millisecond * 1200)
+	polledCount := rankingRequests.Load()
+
+	var responses []*grpcHn.TopStories
+	for range 3 {
+		topStories, err := client.GetTopStories(context.Background(), &grpcHn.TopStoriesRequest{StoryNumber: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, topStories)
+	}
+
+	// THEN
+	if polledCount != 1 {
+		t.Errorf("top stories ranking should have been polled once but was fetched %d times", polledCount)
+	}
+
+	if rankingRequests.Load() != polledCount {
+		t.Errorf("requests should read the polled ranking from cache but it was fetched %d more times", rankingRequests.Load() - polledCount)
+	}
+
+	for _, topStories := range responses {
+		fetchedAt := time.Unix(topStories.GetRankingFetchedAt(), 0)
+		if len(topStories.GetStories()) != 1 || time.Since(fetchedAt) > time.Second * 2 || time.Since(fetchedAt) < 0 {
+			t.Errorf("stories should be served along with the fetch time of their ranking but got %v", topStories)
+		}
+	}
+
+	shutdown()
+	waitServed(t, served, time.Second * 2)
+}
+
+func TestServeShouldExposeRpcCacheAndUpstreamMetrics(t *testing.T) {
+	// GIVEN
+	storyRequested := make(chan struct{}, 1)
+	releaseStory := make(chan struct{})
+	close(releaseStory)
+
+	metricsListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shutdown, served, conn := startServer(t, testConfig(t, newFakeHn(t, storyRequested, releaseStory), 5), metricsListener)
+	client := grpcHn.NewHnServiceClient(conn)
+
+	if _, err := client.GetTopStories(context.Background(), &grpcHn.TopStoriesRequest{StoryNumber: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// WHEN
+	response, err := http.Get("http://" + metricsListener.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+
+	// THEN
+	expectedMetrics := []string{
+		`hnproxy_rpc_requests_total{code="OK",method="GetTopStories",service="hackernews.HnService"} 1`,
+		`hnproxy_rpc_duration_seconds_count{code="OK",method="GetTopStories",service="hackernews.HnService"} 1`,
+		`hnproxy_cache_misses_total{cache="stories"}`,
+		`hnproxy_cache_entries{cache="stories"} 1`,
+		`hnproxy_cache_entries{cache="rankings"} 1`,
+		`hnproxy_upstream_request_duration_seconds_count{endpoint="topstories",status="200"} 1`,
+		`hnproxy_upstream_request_duration_seconds_count{endpoint="item",status="200"} 1`,
+		`hnproxy_upstream_circuit_state{state="closed"} 1`,
+	}
+
+	for _, expectedMetric := range expectedMetrics {
+		if !strings.Contains(string(body), expectedMetric) {
+			t.Errorf("metrics should contain '%s'", expectedMetric)
+		}
+	}
+
+	shutdown()
+	waitServed(t, served, time.Second * 2)
+}
+
+func TestServeShouldOnlyServeAdminServiceOnItsOwnListener(t *testing.T) {
+	// GIVEN
+	storyRequested := make(chan struct{}, 1)
+	releaseStory := make(chan struct{})
+	close(releaseStory)
+
+	shutdown, served, conn, adminConn := startServerWithAdmin(t, testConfig(t, newFakeHn(t, storyRequested, releaseStory), 5))
+
+	// WHEN
+	_, publicErr := grpcHn.NewHnAdminServiceClient(conn).PurgeCache(context.Background(), &grpcHn.PurgeCacheRequest{})
+	_, adminErr := grpcHn.NewHnAdminServiceClient(adminConn).PurgeCache(context.Background(), &grpcHn.PurgeCacheRequest{})
+
+	// THEN
+	if status.Code(publicErr) != codes.Unimplemented {
+		t.Errorf("admin service should not be served on the public listener but got: %v", publicErr)
+	}
+
+	if adminErr != nil {
+		t.Errorf("admin service should be served on the admin listener but got: %v", adminErr)
+	}
+
+	shutdown()
+	waitServed(t, served, time.Second * 2)
+}
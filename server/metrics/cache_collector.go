@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"hackernews/server/cache"
+)
+
+var (
+	cacheHitsDesc = prometheus.NewDesc("hnproxy_cache_hits_total", "Number of lookups which found their entry in cache", []string{"cache"}, nil)
+	cacheMissesDesc = prometheus.NewDesc("hnproxy_cache_misses_total", "Number of lookups which did not find their entry in cache", []string{"cache"}, nil)
+	cacheEvictionsDesc = prometheus.NewDesc("hnproxy_cache_evictions_total", "Number of entries removed to make room for new ones", []string{"cache"}, nil)
+	cacheExpirationsDesc = prometheus.NewDesc("hnproxy_cache_expirations_total", "Number of entries removed because their time to live elapsed", []string{"cache"}, nil)
+	cacheEntriesDesc = prometheus.NewDesc("hnproxy_cache_entries", "Number of entries currently in cache", []string{"cache"}, nil)
+	cacheOldestEntryAgeDesc = prometheus.NewDesc("hnproxy_cache_oldest_entry_age_seconds", "Age of the earliest added entry still in cache, 0 when cache is empty", []string{"cache"}, nil)
+)
+
+// Exports statistics of caches, by cache name. Statistics are read from caches on each scrape rather than duplicated
+type cacheCollector struct {
+	caches map[string]cache.Inspectable
+}
+
+func NewCacheCollector(caches map[string]cache.Inspectable) prometheus.Collector {
+	return &cacheCollector{caches: caches}
+}
+
+func (c *cacheCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- cacheHitsDesc
+	descs <- cacheMissesDesc
+	descs <- cacheEvictionsDesc
+	descs <- cacheExpirationsDesc
+	descs <- cacheEntriesDesc
+	descs <- cacheOldestEntryAgeDesc
+}
+
+func (c *cacheCollector) Collect(metrics chan<- prometheus.Metric) {
+	for name, inspectable := range c.caches {
+		stats := inspectable.Stats()
+
+		metrics <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(stats.Hits), name)
+		metrics <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(stats.Misses), name)
+		metrics <- prometheus.MustNewConstMetric(cacheEvictionsDesc, prometheus.CounterValue, float64(stats.Evictions), name)
+		metrics <- prometheus.MustNewConstMetric(cacheExpirationsDesc, prometheus.CounterValue, float64(stats.Expirations), name)
+		metrics <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(stats.Size), name)
+		metrics <- prometheus.MustNewConstMetric(cacheOldestEntryAgeDesc, prometheus.GaugeValue, stats.OldestEntryAge.Seconds(), name)
+	}
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"hackernews/server/cache"
+)
+
+type MockInspectableCache struct {
+	cache.Inspectable
+	MockedStats func() cache.Stats
+}
+
+func (m MockInspectableCache) Stats() cache.Stats {
+	return m.MockedStats()
+}
+
+func TestCacheCollectorShouldExportStatsOfEveryCache(t *testing.T) {
+	// GIVEN
+	usersStats := cache.Stats{Hits: 3, Misses: 1, Evictions: 2, Expirations: 4, Size: 5, OldestEntryAge: time.Minute}
+	collector := NewCacheCollector(map[string]cache.Inspectable{
+		"users": MockInspectableCache{MockedStats: func() cache.Stats { return usersStats }},
+		"stories": MockInspectableCache{MockedStats: func() cache.Stats { return cache.Stats{} }},
+	})
+
+	expected := `
+# HELP hnproxy_cache_hits_total Number of lookups which found their entry in cache
+# TYPE hnproxy_cache_hits_total counter
+hnproxy_cache_hits_total{cache="stories"} 0
+hnproxy_cache_hits_total{cache="users"} 3
+# HELP hnproxy_cache_evictions_total Number of entries removed to make room for new ones
+# TYPE hnproxy_cache_evictions_total counter
+hnproxy_cache_evictions_total{cache="stories"} 0
+hnproxy_cache_evictions_total{cache="users"} 2
+# HELP hnproxy_cache_oldest_entry_age_seconds Age of the earliest added entry still in cache, 0 when cache is empty
+# TYPE hnproxy_cache_oldest_entry_age_seconds gauge
+hnproxy_cache_oldest_entry_age_seconds{cache="stories"} 0
+hnproxy_cache_oldest_entry_age_seconds{cache="users"} 60
+`
+
+	// WHEN
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"hnproxy_cache_hits_total", "hnproxy_cache_evictions_total", "hnproxy_cache_oldest_entry_age_seconds")
+
+	// THEN
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count := testutil.CollectAndCount(collector); count != 12 {
+		t.Errorf("expected 6 metrics for each of the 2 caches but got %d", count)
+	}
+}
+
+func TestCacheCollectorShouldReadStatsOnEachCollection(t *testing.T) {
+	// GIVEN
+	hits := uint64(1)
+	collector := NewCacheCollector(map[string]cache.Inspectable{
+		"users": MockInspectableCache{MockedStats: func() cache.Stats { return cache.Stats{Hits: hits} }},
+	})
+
+	expected := `
+# HELP hnproxy_cache_hits_total Number of lookups which found their entry in cache
+# TYPE hnproxy_cache_hits_total counter
+hnproxy_cache_hits_total{cache="users"} 2
+`
+
+	// WHEN
+	hits = 2
+
+	// THEN
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "hnproxy_cache_hits_total"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"hackernews/server/upstream"
+)
+
+var (
+	circuitStateDesc = prometheus.NewDesc("hnproxy_upstream_circuit_state", "State of the circuit breaker of HackerNews API, 1 for the current state and 0 for others", []string{"state"}, nil)
+	circuitRejectedDesc = prometheus.NewDesc("hnproxy_upstream_circuit_rejected_requests_total", "Number of requests to HackerNews API failed fast by its circuit breaker", nil, nil)
+)
+
+var circuitStates = []upstream.CircuitState{upstream.CircuitClosed, upstream.CircuitOpen, upstream.CircuitHalfOpen}
+
+// Exports the state of the circuit breaker of HackerNews API, read from the breaker on each scrape
+type circuitBreakerCollector struct {
+	breaker *upstream.CircuitBreaker
+}
+
+func NewCircuitBreakerCollector(breaker *upstream.CircuitBreaker) prometheus.Collector {
+	return &circuitBreakerCollector{breaker: breaker}
+}
+
+func (c *circuitBreakerCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- circuitStateDesc
+	descs <- circuitRejectedDesc
+}
+
+func (c *circuitBreakerCollector) Collect(metrics chan<- prometheus.Metric) {
+	current := c.breaker.State()
+
+	for _, state := range circuitStates {
+		value := 0.0
+		if state == current {
+			value = 1
+		}
+
+		metrics <- prometheus.MustNewConstMetric(circuitStateDesc, prometheus.GaugeValue, value, state.String())
+	}
+
+	metrics <- prometheus.MustNewConstMetric(circuitRejectedDesc, prometheus.CounterValue, float64(c.breaker.RejectedCount()))
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	hn "github.com/peterhellberg/hn"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"hackernews/server/upstream"
+)
+
+type MockItemsService struct {
+	MockedGet func(id int) (*hn.Item, error)
+}
+
+func (m MockItemsService) Get(_ context.Context, id int) (*hn.Item, error) {
+	return m.MockedGet(id)
+}
+
+func TestCircuitBreakerCollectorShouldExportStateAndRejectedRequests(t *testing.T) {
+	// GIVEN
+	breaker := upstream.NewCircuitBreaker(1, time.Minute, func(upstream.CircuitState) {})
+	defer breaker.Close()
+
+	items := MockItemsService{MockedGet: func(id int) (*hn.Item, error) {
+		return nil, &upstream.StatusError{Path: "item/1.json", StatusCode: http.StatusBadGateway}
+	}}
+	client := upstream.NewCircuitBreakingClient(upstream.Client{Items: items}, breaker)
+
+	collector := NewCircuitBreakerCollector(breaker)
+
+	// WHEN
+	for range 3 {
+		client.Items.Get(context.Background(), 1)
+	}
+
+	// THEN
+	expected := `
+# HELP hnproxy_upstream_circuit_state State of the circuit breaker of HackerNews API, 1 for the current state and 0 for others
+# TYPE hnproxy_upstream_circuit_state gauge
+hnproxy_upstream_circuit_state{state="closed"} 0
+hnproxy_upstream_circuit_state{state="half-open"} 0
+hnproxy_upstream_circuit_state{state="open"} 1
+# HELP hnproxy_upstream_circuit_rejected_requests_total Number of requests to HackerNews API failed fast by its circuit breaker
+# TYPE hnproxy_upstream_circuit_rejected_requests_total counter
+hnproxy_upstream_circuit_rejected_requests_total 2
+`
+
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Counts and times every RPC served, by service, method and status code.
+// Streams are timed from their opening to their end
+type RpcMetrics struct {
+	requests *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+}
+
+func NewRpcMetrics(registerer prometheus.Registerer) *RpcMetrics {
+	rpcMetrics := &RpcMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hnproxy_rpc_requests_total",
+			Help: "Number of RPCs completed, by service, method and status code",
+		}, []string{"service", "method", "code"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "hnproxy_rpc_duration_seconds",
+			Help: "Duration of RPCs, by service, method and status code",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"service", "method", "code"}),
+	}
+
+	registerer.MustRegister(rpcMetrics.requests, rpcMetrics.durations)
+
+	return rpcMetrics
+}
+
+func (m *RpcMetrics) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		response, err := handler(ctx, request)
+		m.observe(info.FullMethod, start, err)
+
+		return response, err
+	}
+}
+
+func (m *RpcMetrics) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(server, stream)
+		m.observe(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func (m *RpcMetrics) observe(fullMethod string, start time.Time, err error) {
+	service, method := splitFullMethod(fullMethod)
+	code := status.Code(err).String()
+
+	m.requests.WithLabelValues(service, method, code).Inc()
+	m.durations.WithLabelValues(service, method, code).Observe(time.Since(start).Seconds())
+}
+
+// Splits a gRPC full method, e.g. /hackernews.HnService/GetTopStories, into its service and method names
+func splitFullMethod(fullMethod string) (string, string) {
+	service, method, found := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !found {
+		return "unknown", fullMethod
+	}
+
+	return service, method
+}
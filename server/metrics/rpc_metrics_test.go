@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryInterceptorShouldCountRequestsByMethodAndCode(t *testing.T) {
+	// GIVEN
+	rpcMetrics := NewRpcMetrics(prometheus.NewRegistry())
+	interceptor := rpcMetrics.UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/hackernews.HnService/GetItem"}
+
+	succeeding := func(ctx context.Context, request any) (any, error) {
+		return "item", nil
+	}
+	failing := func(ctx context.Context, request any) (any, error) {
+		return nil, status.Error(codes.NotFound, "item not found")
+	}
+
+	// WHEN
+	interceptor(context.Background(), nil, info, succeeding)
+	interceptor(context.Background(), nil, info, succeeding)
+	_, err := interceptor(context.Background(), nil, info, failing)
+
+	// THEN
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("handler error should be returned as is but got: %v", err)
+	}
+
+	if count := testutil.ToFloat64(rpcMetrics.requests.WithLabelValues("hackernews.HnService", "GetItem", "OK")); count != 2 {
+		t.Errorf("expected 2 successful requests but got %v", count)
+	}
+
+	if count := testutil.ToFloat64(rpcMetrics.requests.WithLabelValues("hackernews.HnService", "GetItem", "NotFound")); count != 1 {
+		t.Errorf("expected 1 not found request but got %v", count)
+	}
+
+	if count := testutil.CollectAndCount(rpcMetrics.durations); count != 2 {
+		t.Errorf("expected durations of 2 method and code pairs but got %d", count)
+	}
+}
+
+func TestStreamInterceptorShouldCountStreamsByMethodAndCode(t *testing.T) {
+	// GIVEN
+	rpcMetrics := NewRpcMetrics(prometheus.NewRegistry())
+	info := &grpc.StreamServerInfo{FullMethod: "/hackernews.HnService/WatchTopStories", IsServerStream: true}
+
+	// WHEN
+	rpcMetrics.StreamInterceptor()(nil, nil, info, func(server any, stream grpc.ServerStream) error {
+		return status.Error(codes.Unavailable, "server is shutting down")
+	})
+
+	// THEN
+	if count := testutil.ToFloat64(rpcMetrics.requests.WithLabelValues("hackernews.HnService", "WatchTopStories", "Unavailable")); count != 1 {
+		t.Errorf("expected 1 unavailable stream but got %v", count)
+	}
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Times requests sent to HackerNews API by the stories and users proxies, and counts their failures, by endpoint
+type upstreamTransport struct {
+	next http.RoundTripper
+	durations *prometheus.HistogramVec
+	errors *prometheus.CounterVec
+}
+
+func NewUpstreamTransport(next http.RoundTripper, registerer prometheus.Registerer) http.RoundTripper {
+	transport := &upstreamTransport{
+		next: next,
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "hnproxy_upstream_request_duration_seconds",
+			Help: "Duration of requests to HackerNews API, by endpoint and HTTP status, 'error' when no response was received",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"endpoint", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hnproxy_upstream_request_errors_total",
+			Help: "Number of requests to HackerNews API which failed or got a server error, by endpoint",
+		}, []string{"endpoint"}),
+	}
+
+	registerer.MustRegister(transport.durations, transport.errors)
+
+	return transport
+}
+
+func (t *upstreamTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := t.next.RoundTrip(request)
+	duration := time.Since(start)
+
+	endpoint := endpointOf(request.URL.Path)
+	responseStatus := "error"
+
+	if err == nil {
+		responseStatus = strconv.Itoa(response.StatusCode)
+	}
+
+	t.durations.WithLabelValues(endpoint, responseStatus).Observe(duration.Seconds())
+
+	if err != nil || response.StatusCode >= http.StatusInternalServerError {
+		t.errors.WithLabelValues(endpoint).Inc()
+	}
+
+	return response, err
+}
+
+// Endpoints of HackerNews API requested by their name alone, the other ones being followed by an id
+var namedEndpoints = map[string]bool{
+	"topstories": true,
+	"newstories": true,
+	"beststories": true,
+	"askstories": true,
+	"showstories": true,
+	"jobstories": true,
+	"maxitem": true,
+	"updates": true,
+}
+
+// Label of requests to paths which are not a known endpoint, so that requested paths cannot create series
+const otherEndpoint = "other"
+
+// HackerNews API endpoint of a request path. Ids are left out to keep labels bounded, e.g. /v0/item/8863.json gives item
+func endpointOf(requestPath string) string {
+	directory, file := path.Split(strings.TrimSuffix(requestPath, ".json"))
+
+	if parent := path.Base(directory); parent == "item" || parent == "user" {
+		return parent
+	} else if namedEndpoints[file] {
+		return file
+	}
+
+	return otherEndpoint
+}
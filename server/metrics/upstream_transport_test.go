@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestEndpointOfShouldLeaveIdsOut(t *testing.T) {
+	testCases := map[string]string{
+		"/v0/item/8863.json": "item",
+		"/v0/user/jl.json": "user",
+		"/v0/topstories.json": "topstories",
+		"/v0/maxitem.json": "maxitem",
+		"/v0/user/a/b/c.json": "other",
+		"/v0/unknown.json": "other",
+	}
+
+	for requestPath, expectedEndpoint := range testCases {
+		t.Run(requestPath, func(t *testing.T) {
+			// WHEN
+			endpoint := endpointOf(requestPath)
+
+			// THEN
+			if endpoint != expectedEndpoint {
+				t.Errorf("expected endpoint '%s' but got '%s'", expectedEndpoint, endpoint)
+			}
+		})
+	}
+}
+
+func TestUpstreamTransportShouldTimeRequestsAndCountErrors(t *testing.T) {
+	// GIVEN
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v0/item/2.json" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer upstream.Close()
+
+	transport := NewUpstreamTransport(http.DefaultTransport, prometheus.NewRegistry()).(*upstreamTransport)
+	client := &http.Client{Transport: transport}
+
+	// WHEN
+	for _, requestPath := range []string{"/v0/item/1.json", "/v0/item/2.json", "/v0/user/pg.json"} {
+		response, err := client.Get(upstream.URL + requestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+	}
+
+	// THEN
+	if count := testutil.CollectAndCount(transport.durations); count != 3 {
+		t.Errorf("expected durations of 3 endpoint and status pairs but got %d", count)
+	}
+
+	if count := testutil.ToFloat64(transport.errors.WithLabelValues("item")); count != 1 {
+		t.Errorf("expected 1 item error but got %v", count)
+	}
+
+	if count := testutil.ToFloat64(transport.errors.WithLabelValues("user")); count != 0 {
+		t.Errorf("expected no user error but got %v", count)
+	}
+}
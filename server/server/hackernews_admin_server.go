@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	grpcHn "hackernews/generated"
+
+	"hackernews/server/cache"
+	sts "hackernews/server/stories"
+	us "hackernews/server/users"
+)
+
+const usersCacheName string = "users"
+const storiesCacheName string = "stories"
+const rankingsCacheName string = "rankings"
+
+type hackernewsAdminServer struct {
+	grpcHn.UnimplementedHnAdminServiceServer // necessary for grpc to work
+	UserCache cache.Cache[string, *us.User]
+	StoriesCache cache.Cache[int, *sts.Story]
+	// Every cache of the proxy, by name
+	Caches map[string]cache.Inspectable
+}
+
+func NewHnAdminServer(userCache cache.Cache[string, *us.User], storiesCache cache.Cache[int, *sts.Story], rankingsCache cache.Cache[sts.StoryList, *sts.Ranking]) hackernewsAdminServer {
+	return hackernewsAdminServer{
+		UserCache: userCache,
+		StoriesCache: storiesCache,
+		Caches: map[string]cache.Inspectable{
+			usersCacheName: userCache,
+			storiesCacheName: storiesCache,
+			rankingsCacheName: rankingsCache,
+		},
+	}
+}
+
+// Gets usage statistics of the cache whose name is requested, or of every cache sorted by name when no name is provided
+func (s *hackernewsAdminServer) GetCacheStats(_ context.Context, statsRequest *grpcHn.CacheStatsRequest) (*grpcHn.CacheStatsList, error) {
+	names, err := s.requestedCacheNames(statsRequest.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	var statsList = make([]*grpcHn.CacheStats, len(names))
+
+	for i, name := range names {
+		statsList[i] = mapCacheStats(name, s.Caches[name].Stats())
+	}
+
+	return &grpcHn.CacheStatsList{Caches: statsList}, nil
+}
+
+// Removes user from cache, so that its next request fetches it again from HackerNews
+func (s *hackernewsAdminServer) InvalidateUser(_ context.Context, invalidateRequest *grpcHn.InvalidateUserRequest) (*grpcHn.InvalidationResult, error) {
+	if invalidateRequest.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user nickname is required to invalidate it")
+	}
+
+	s.UserCache.Delete(invalidateRequest.GetName())
+
+	return &grpcHn.InvalidationResult{}, nil
+}
+
+// Removes item from cache, so that its next request fetches it again from HackerNews
+func (s *hackernewsAdminServer) InvalidateItem(_ context.Context, invalidateRequest *grpcHn.InvalidateItemRequest) (*grpcHn.InvalidationResult, error) {
+	if invalidateRequest.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "a positive item id is required to invalidate it")
+	}
+
+	s.StoriesCache.Delete(int(invalidateRequest.GetId()))
+
+	return &grpcHn.InvalidationResult{}, nil
+}
+
+// Removes every entry of the cache whose name is requested, or of every cache when no name is provided
+func (s *hackernewsAdminServer) PurgeCache(_ context.Context, purgeRequest *grpcHn.PurgeCacheRequest) (*grpcHn.PurgeCacheResult, error) {
+	names, err := s.requestedCacheNames(purgeRequest.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	purgedCount := 0
+	for _, name := range names {
+		purgedCount += s.Caches[name].Clear()
+	}
+
+	return &grpcHn.PurgeCacheResult{PurgedEntryCount: uint64(purgedCount)}, nil
+}
+
+// Lists keys of a cache, up to the requested max number of keys
+func (s *hackernewsAdminServer) ListCacheKeys(_ context.Context, keysRequest *grpcHn.ListCacheKeysRequest) (*grpcHn.CacheKeys, error) {
+	if keysRequest.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "cache name is required to list its keys")
+	}
+
+	names, err := s.requestedCacheNames(keysRequest.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	keys := s.Caches[names[0]].FormattedKeys()
+	totalKeyCount := len(keys)
+
+	if keysRequest.GetMaxKeys() > 0 {
+		keys = keys[:min(len(keys), int(keysRequest.GetMaxKeys()))]
+	}
+
+	return &grpcHn.CacheKeys{Keys: keys, TotalKeyCount: uint64(totalKeyCount)}, nil
+}
+
+// Names of the caches targeted by a request: the one whose name is given, or every cache sorted by name when name is empty
+func (s *hackernewsAdminServer) requestedCacheNames(name string) ([]string, error) {
+	if name == "" {
+		return sortedCacheNames(s.Caches), nil
+	} else if _, cacheExists := s.Caches[name]; !cacheExists {
+		return nil, status.Errorf(codes.NotFound, "unknown cache '%s'", name)
+	}
+
+	return []string{name}, nil
+}
+
+func sortedCacheNames(caches map[string]cache.Inspectable) []string {
+	var names []string
+
+	for name := range caches {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func mapCacheStats(name string, stats cache.Stats) *grpcHn.CacheStats {
+	return &grpcHn.CacheStats{
+		Name: name,
+		Hits: stats.Hits,
+		Misses: stats.Misses,
+		Evictions: stats.Evictions,
+		Expirations: stats.Expirations,
+		Size: uint64(stats.Size),
+		OldestEntryAgeSeconds: int64(stats.OldestEntryAge.Seconds()),
+	}
+}
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	grpcHn "hackernews/generated"
+
+	"hackernews/server/cache"
+	sts "hackernews/server/stories"
+	us "hackernews/server/users"
+)
+
+type MockInspectableCache struct {
+	MockedStats func() cache.Stats
+	MockedFormattedKeys func() []string
+	MockedClear func() int
+}
+
+func (m MockInspectableCache) Stats() cache.Stats {
+	return m.MockedStats()
+}
+
+func (m MockInspectableCache) FormattedKeys() []string {
+	return m.MockedFormattedKeys()
+}
+
+func (m MockInspectableCache) Clear() int {
+	return m.MockedClear()
+}
+
+func mockCacheWithStats(stats cache.Stats) MockInspectableCache {
+	return MockInspectableCache{MockedStats: func() cache.Stats { return stats }}
+}
+
+func TestGetCacheStatsShouldReturnEveryCacheSortedByName(t *testing.T) {
+	// GIVEN
+	server := NewHnAdminServer(nil, nil, nil)
+	server.Caches = map[string]cache.Inspectable{
+		"users": mockCacheWithStats(cache.Stats{Hits: 1}),
+		"stories": mockCacheWithStats(cache.Stats{Hits: 2, Misses: 3, Evictions: 4, Expirations: 5, Size: 6, OldestEntryAge: time.Minute}),
+	}
+
+	// WHEN
+	statsList, err := server.GetCacheStats(context.Background(), &grpcHn.CacheStatsRequest{})
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if len(statsList.GetCaches()) != 2 {
+		t.Fatalf("expected stats of 2 caches but got %d", len(statsList.GetCaches()))
+	}
+
+	stories := statsList.GetCaches()[0]
+	if stories.GetName() != "stories" || statsList.GetCaches()[1].GetName() != "users" {
+		t.Errorf("caches should be sorted by name but got '%s' first", stories.GetName())
+	}
+
+	if stories.GetHits() != 2 || stories.GetMisses() != 3 || stories.GetEvictions() != 4 || stories.GetExpirations() != 5 ||
+		stories.GetSize() != 6 || stories.GetOldestEntryAgeSeconds() != 60 {
+		t.Errorf("stats were not all mapped: %+v", stories)
+	}
+}
+
+func TestGetCacheStatsShouldReturnRequestedCacheOnly(t *testing.T) {
+	// GIVEN
+	server := NewHnAdminServer(nil, nil, nil)
+	server.Caches = map[string]cache.Inspectable{
+		"users": mockCacheWithStats(cache.Stats{Hits: 1}),
+		"stories": mockCacheWithStats(cache.Stats{Hits: 2}),
+	}
+
+	// WHEN
+	statsList, err := server.GetCacheStats(context.Background(), &grpcHn.CacheStatsRequest{Name: "users"})
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if len(statsList.GetCaches()) != 1 || statsList.GetCaches()[0].GetHits() != 1 {
+		t.Errorf("expected stats of users cache only but got %+v", statsList.GetCaches())
+	}
+}
+
+func TestGetCacheStatsShouldRejectUnknownCache(t *testing.T) {
+	// GIVEN
+	server := NewHnAdminServer(nil, nil, nil)
+
+	// WHEN
+	_, err := server.GetCacheStats(context.Background(), &grpcHn.CacheStatsRequest{Name: "unknown"})
+
+	// THEN
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected not found but got: %v", err)
+	}
+}
+
+func TestInvalidateUserShouldRemoveUserFromCacheOnly(t *testing.T) {
+	// GIVEN
+	userCache := cache.NewLruTimeToLiveCache[string, *us.User](time.Minute, 10)
+	userCache.Add("antwan", &us.User{Nickname: "antwan"})
+	userCache.Add("fra", &us.User{Nickname: "fra"})
+
+	server := NewHnAdminServer(userCache, cache.NewLruTimeToLiveCache[int, *sts.Story](time.Minute, 10), nil)
+
+	// WHEN
+	_, err := server.InvalidateUser(context.Background(), &grpcHn.InvalidateUserRequest{Name: "antwan"})
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	}
+
+	if _, isCached := userCache.Get("antwan"); isCached {
+		t.Error("invalidated user should not be cached anymore")
+	} else if _, isCached := userCache.Get("fra"); !isCached {
+		t.Error("other users should stay cached")
+	}
+}
+
+func TestInvalidateUserShouldRejectEmptyNickname(t *testing.T) {
+	// GIVEN
+	server := NewHnAdminServer(cache.NewLruTimeToLiveCache[string, *us.User](time.Minute, 10), nil, nil)
+
+	// WHEN
+	_, err := server.InvalidateUser(context.Background(), &grpcHn.InvalidateUserRequest{})
+
+	// THEN
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected invalid argument but got: %v", err)
+	}
+}
+
+func TestInvalidateItemShouldRemoveItemFromCache(t *testing.T) {
+	// GIVEN
+	storiesCache := cache.NewLruTimeToLiveCache[int, *sts.Story](time.Minute, 10)
+	storiesCache.Add(42, &sts.Story{Id: 42})
+
+	server := NewHnAdminServer(nil, storiesCache, nil)
+
+	// WHEN
+	_, err := server.InvalidateItem(context.Background(), &grpcHn.InvalidateItemRequest{Id: 42})
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if _, isCached := storiesCache.Get(42); isCached {
+		t.Error("invalidated item should not be cached anymore")
+	}
+}
+
+func TestInvalidateItemShouldRejectNonPositiveId(t *testing.T) {
+	// GIVEN
+	server := NewHnAdminServer(nil, cache.NewLruTimeToLiveCache[int, *sts.Story](time.Minute, 10), nil)
+
+	// WHEN
+	_, err := server.InvalidateItem(context.Background(), &grpcHn.InvalidateItemRequest{Id: 0})
+
+	// THEN
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected invalid argument but got: %v", err)
+	}
+}
+
+func TestPurgeCacheShouldClearRequestedCaches(t *testing.T) {
+	testCases := []struct{
+		name string
+		cacheName string
+		expectedPurgedCount uint64
+		expectedUsersLeft int
+	}{
+		{name: "every cache", cacheName: "", expectedPurgedCount: 4, expectedUsersLeft: 0},
+		{name: "single cache", cacheName: "stories", expectedPurgedCount: 1, expectedUsersLeft: 2},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// GIVEN
+			userCache := cache.NewLruTimeToLiveCache[string, *us.User](time.Minute, 10)
+			userCache.Add("antwan", nil)
+			userCache.Add("fra", nil)
+			storiesCache := cache.NewLruTimeToLiveCache[int, *sts.Story](time.Minute, 10)
+			storiesCache.Add(42, nil)
+			rankingsCache := cache.NewLruTimeToLiveCache[sts.StoryList, *sts.Ranking](time.Minute, 10)
+			rankingsCache.Add(sts.TopStories, &sts.Ranking{Ids: []int{42}})
+
+			server := NewHnAdminServer(userCache, storiesCache, rankingsCache)
+
+			// WHEN
+			result, err := server.PurgeCache(context.Background(), &grpcHn.PurgeCacheRequest{Name: testCase.cacheName})
+
+			// THEN
+			if err != nil {
+				t.Fatalf("no error should be met but got: %v", err)
+			} else if result.GetPurgedEntryCount() != testCase.expectedPurgedCount {
+				t.Errorf("expected %d purged entries but got %d", testCase.expectedPurgedCount, result.GetPurgedEntryCount())
+			}
+
+			if len(userCache.Keys()) != testCase.expectedUsersLeft {
+				t.Errorf("expected %d users left in cache but got %d", testCase.expectedUsersLeft, len(userCache.Keys()))
+			} else if len(storiesCache.Keys()) != 0 {
+				t.Error("stories cache should have been purged")
+			}
+		})
+	}
+}
+
+func TestListCacheKeysShouldLimitListedKeys(t *testing.T) {
+	// GIVEN
+	storiesCache := cache.NewLruTimeToLiveCache[int, *sts.Story](time.Minute, 10)
+	storiesCache.Add(1, nil)
+	storiesCache.Add(2, nil)
+	storiesCache.Add(3, nil)
+
+	server := NewHnAdminServer(cache.NewLruTimeToLiveCache[string, *us.User](time.Minute, 10), storiesCache, nil)
+
+	// WHEN
+	keys, err := server.ListCacheKeys(context.Background(), &grpcHn.ListCacheKeysRequest{Name: "stories", MaxKeys: 2})
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if len(keys.GetKeys()) != 2 || keys.GetKeys()[0] != "3" || keys.GetKeys()[1] != "2" {
+		t.Errorf("expected 2 most recently used keys but got %v", keys.GetKeys())
+	} else if keys.GetTotalKeyCount() != 3 {
+		t.Errorf("expected 3 keys in total but got %d", keys.GetTotalKeyCount())
+	}
+}
+
+func TestListCacheKeysShouldNameRankingsAfterTheirList(t *testing.T) {
+	// GIVEN
+	rankingsCache := cache.NewLruTimeToLiveCache[sts.StoryList, *sts.Ranking](time.Minute, 10)
+	rankingsCache.Add(sts.TopStories, &sts.Ranking{Ids: []int{1}})
+
+	server := NewHnAdminServer(nil, nil, rankingsCache)
+
+	// WHEN
+	keys, err := server.ListCacheKeys(context.Background(), &grpcHn.ListCacheKeysRequest{Name: "rankings"})
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if len(keys.GetKeys()) != 1 || keys.GetKeys()[0] != "topstories" {
+		t.Errorf("expected top stories ranking key but got %v", keys.GetKeys())
+	}
+}
+
+func TestListCacheKeysShouldRequireCacheName(t *testing.T) {
+	// GIVEN
+	server := NewHnAdminServer(nil, nil, nil)
+
+	// WHEN
+	_, err := server.ListCacheKeys(context.Background(), &grpcHn.ListCacheKeysRequest{})
+
+	// THEN
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected invalid argument but got: %v", err)
+	}
+}
@@ -2,60 +2,265 @@ package server
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"strconv"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	grpcHn "hackernews/generated"
 
+	"hackernews/server/frontpage"
 	sts "hackernews/server/stories"
 	us "hackernews/server/users"
 )
 
+const maxCommentTreeDepth uint32 = 10
+const maxCommentTreeChildren uint32 = 100
+
+// Trailer of streams of stale stories, telling the time in seconds since the oldest of them was fetched
+const staleAgeTrailer string = "hnproxy-stale-age-seconds"
+// Trailer of streams of stories, telling the unix time in seconds when their ranking was fetched
+const rankingFetchedAtTrailer string = "hnproxy-ranking-fetched-at"
+
 type hackernewsProxyServer struct {
     grpcHn.UnimplementedHnServiceServer // necessary for grpc to work
 	UserService us.UserService
 	StoriesService sts.StoriesService
+	TopStoriesWatcher *frontpage.TopStoriesWatcher
+	// Max number of stories, or page size, a single request can ask for
+	MaxStoriesPerRequest uint32
 }
 
-func NewHnProxyServer(storiesService sts.StoriesService, userService us.UserService) hackernewsProxyServer {
+func NewHnProxyServer(storiesService sts.StoriesService, userService us.UserService, topStoriesWatcher *frontpage.TopStoriesWatcher, maxStoriesPerRequest uint32) hackernewsProxyServer {
 	return hackernewsProxyServer{
 		StoriesService: storiesService,
 		UserService: userService,
+		TopStoriesWatcher: topStoriesWatcher,
+		MaxStoriesPerRequest: maxStoriesPerRequest,
 	}
 }
 
-// Fetches first nth top stories and their basic information
-func (s *hackernewsProxyServer) GetTopStories(_ context.Context, storiesRequest *grpcHn.TopStoriesRequest) (*grpcHn.TopStories, error) {
-	stories, err := s.StoriesService.GetTopStories(storiesRequest.GetStoryNumber())
+// Fetches first nth top stories and their basic information, or a page of them when a page size or token is provided
+func (s *hackernewsProxyServer) GetTopStories(ctx context.Context, storiesRequest *grpcHn.TopStoriesRequest) (*grpcHn.TopStories, error) {
+	return s.getStories(ctx, sts.TopStories, storiesRequest)
+}
 
-	if err != nil {
-		log.Printf("Error while retrieving top stories. Cause: %s\n", err.Error())
-		return nil, status.Errorf(codes.Internal, "internal error while retrieving top stories. Caused by: %s", err.Error())
+// Streams first nth top stories as soon as each of them is available. Stories are sent along with their rank, but not in rank order
+func (s *hackernewsProxyServer) StreamTopStories(storiesRequest *grpcHn.TopStoriesRequest, stream grpc.ServerStreamingServer[grpcHn.RankedStory]) error {
+	if err := s.validateStoryCount(storiesRequest.GetStoryNumber(), "story number"); err != nil {
+		return err
 	}
 
-	var mappedStories = make([]*grpcHn.Story, len(*stories))
+	freshness, err := s.StoriesService.StreamTopStories(stream.Context(), storiesRequest.GetStoryNumber(), func(rank int, story *sts.Story) error {
+		return stream.Send(&grpcHn.RankedStory{
+			Rank: uint32(rank + 1),
+			Story: mapStory(story),
+		})
+	})
+
+	if err != nil && stream.Context().Err() != nil {
+		return status.FromContextError(stream.Context().Err()).Err()
+	} else if status.Code(err) == codes.Unavailable {
+		return err
+	} else if err != nil {
+		slog.Error("error while streaming top stories", "cause", err)
+		return status.Errorf(codes.Internal, "internal error while streaming top stories. Caused by: %s", err.Error())
+	}
+
+	stream.SetTrailer(metadata.Pairs(rankingFetchedAtTrailer, strconv.FormatInt(freshness.RankingFetchedAt.Unix(), 10)))
+	if freshness.Stale {
+		stream.SetTrailer(metadata.Pairs(staleAgeTrailer, strconv.Itoa(int(freshness.Age.Seconds()))))
+	}
+
+	return nil
+}
+
+// Streams front page changes until the client leaves: stories entering or leaving it, moving rank or changing score.
+// The current front page is first sent as entered stories
+func (s *hackernewsProxyServer) WatchTopStories(_ *grpcHn.WatchTopStoriesRequest, stream grpc.ServerStreamingServer[grpcHn.FrontPageEvent]) error {
+	events, unsubscribe := s.TopStoriesWatcher.Subscribe()
+	defer unsubscribe()
 
-	for i, story := range *stories {
-		mappedStories[i] = &grpcHn.Story {
-			Title: story.Title,
-			Url: story.Url,
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case batch, isWatching := <-events:
+			if !isWatching && s.TopStoriesWatcher.IsClosed() {
+				return status.Error(codes.Unavailable, "server is shutting down")
+			} else if !isWatching {
+				return status.Error(codes.ResourceExhausted, "front page events were not received fast enough")
+			}
+
+			for _, event := range batch {
+				if err := stream.Send(mapFrontPageEvent(event)); err != nil {
+					return err
+				}
+			}
 		}
 	}
+}
+
+// Fetches first nth stories of any HackerNews list (new, best, ask...) and their basic information
+func (s *hackernewsProxyServer) GetStories(ctx context.Context, storiesRequest *grpcHn.StoriesRequest) (*grpcHn.TopStories, error) {
+	list, listExists := storyLists[storiesRequest.GetKind()]
+	if !listExists {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown story list kind '%s'", storiesRequest.GetKind())
+	}
+
+	return s.getStories(ctx, list, storiesRequest)
+}
+
+// Request of stories, either through TopStoriesRequest or StoriesRequest
+type storiesRequest interface {
+	GetStoryNumber() uint32
+	GetOffset() uint32
+	GetPageSize() uint32
+	GetPageToken() string
+}
+
+// Fetches first nth stories of list, or a page of them when a page size or token is provided
+func (s *hackernewsProxyServer) getStories(ctx context.Context, list sts.StoryList, storiesRequest storiesRequest) (*grpcHn.TopStories, error) {
+	if isPaginated(storiesRequest) {
+		return s.getStoriesPage(ctx, list, storiesRequest)
+	}
+
+	if err := s.validateStoryCount(storiesRequest.GetStoryNumber(), "story number"); err != nil {
+		return nil, err
+	}
+
+	page, err := s.StoriesService.GetStories(ctx, list, storiesRequest.GetStoryNumber())
+
+	// fetches of requests given up by their client are aborted, which is not an internal error,
+	// nor is HackerNews API being unavailable while its circuit breaker is open
+	if err != nil && ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	} else if status.Code(err) == codes.Unavailable {
+		return nil, err
+	} else if err != nil {
+		slog.Error("error while retrieving stories", "cause", err)
+		return nil, status.Errorf(codes.Internal, "internal error while retrieving stories. Caused by: %s", err.Error())
+	}
+
+	return &grpcHn.TopStories{
+		Stories: mapStories(&page.Stories),
+		AvailableStoryCount: uint32(page.AvailableCount),
+		Stale: page.Stale,
+		StaleAgeSeconds: uint32(page.Age.Seconds()),
+		RankingFetchedAt: page.RankingFetchedAt.Unix(),
+	}, nil
+}
+
+// Rejects story counts the proxy does not serve: none at all, or more than its limit per request
+func (s *hackernewsProxyServer) validateStoryCount(storyCount uint32, countName string) error {
+	if storyCount == 0 {
+		return status.Errorf(codes.InvalidArgument, "%s must be a positive number", countName)
+	} else if storyCount > s.MaxStoriesPerRequest {
+		return status.Errorf(codes.InvalidArgument, "%s cannot exceed %d", countName, s.MaxStoriesPerRequest)
+	}
+
+	return nil
+}
+
+func isPaginated(storiesRequest storiesRequest) bool {
+	return storiesRequest.GetPageSize() > 0 || storiesRequest.GetPageToken() != ""
+}
+
+func (s *hackernewsProxyServer) getStoriesPage(ctx context.Context, list sts.StoryList, storiesRequest storiesRequest) (*grpcHn.TopStories, error) {
+	if err := s.validateStoryCount(storiesRequest.GetPageSize(), "page size"); err != nil {
+		return nil, err
+	}
+
+	page, err := s.StoriesService.GetStoriesPage(ctx, list, sts.PageRequest{
+		Offset: storiesRequest.GetOffset(),
+		PageSize: storiesRequest.GetPageSize(),
+		PageToken: storiesRequest.GetPageToken(),
+	})
+
+	if status.Code(err) == codes.InvalidArgument {
+		return nil, err
+	} else if err != nil && ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	} else if status.Code(err) == codes.Unavailable {
+		return nil, err
+	} else if err != nil {
+		slog.Error("error while retrieving stories page", "cause", err)
+		return nil, status.Errorf(codes.Internal, "internal error while retrieving stories page. Caused by: %s", err.Error())
+	}
+
+	return &grpcHn.TopStories{
+		Stories: mapStories(&page.Stories),
+		NextPageToken: page.NextPageToken,
+		AvailableStoryCount: uint32(page.AvailableCount),
+		Stale: page.Stale,
+		StaleAgeSeconds: uint32(page.Age.Seconds()),
+		RankingFetchedAt: page.RankingFetchedAt.Unix(),
+	}, nil
+}
+
+// Fetches every detail of an item (story, comment, job, poll...) based on its id
+func (s *hackernewsProxyServer) GetItem(ctx context.Context, itemRequest *grpcHn.ItemRequest) (*grpcHn.Item, error) {
+	if itemRequest.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "a positive item id must be provided to fetch item details")
+	}
+
+	item, err := s.StoriesService.GetItem(ctx, int(itemRequest.GetId()))
+
+	if err != nil && ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	} else if status.Code(err) == codes.Unavailable {
+		return nil, err
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not get item information. Caused by: %s", err.Error())
+	} else if item == nil {
+		return nil, status.Errorf(codes.NotFound, "item '%d' not found", itemRequest.GetId())
+	}
+
+	return mapItem(item), nil
+}
+
+// Fetches the discussion under an item, as a tree of comments limited in depth and in children per comment
+func (s *hackernewsProxyServer) GetCommentTree(ctx context.Context, treeRequest *grpcHn.CommentTreeRequest) (*grpcHn.CommentTree, error) {
+	if treeRequest.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "a positive item id must be provided to fetch its comments")
+	} else if treeRequest.GetMaxDepth() > maxCommentTreeDepth {
+		return nil, status.Errorf(codes.InvalidArgument, "comment tree depth cannot exceed %d", maxCommentTreeDepth)
+	} else if treeRequest.GetMaxChildren() > maxCommentTreeChildren {
+		return nil, status.Errorf(codes.InvalidArgument, "comment tree children per item cannot exceed %d", maxCommentTreeChildren)
+	}
+
+	tree, err := s.StoriesService.GetCommentTree(ctx, int(treeRequest.GetId()), treeRequest.GetMaxDepth(), treeRequest.GetMaxChildren())
+
+	if err != nil && ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	} else if status.Code(err) == codes.Unavailable {
+		return nil, err
+	} else if err != nil {
+		slog.Error("error while retrieving comments of item", "item", treeRequest.GetId(), "cause", err)
+		return nil, status.Errorf(codes.Internal, "could not get comment tree. Caused by: %s", err.Error())
+	} else if tree == nil {
+		return nil, status.Errorf(codes.NotFound, "item '%d' not found", treeRequest.GetId())
+	}
 
-    return &grpcHn.TopStories{Stories: mappedStories}, nil
+	return mapCommentTree(tree), nil
 }
 
 // Fetches information about a user based on his/her nickname
-func (s *hackernewsProxyServer) Whois(_ context.Context, userRequest *grpcHn.UserInfoRequest) (*grpcHn.User, error){
+func (s *hackernewsProxyServer) Whois(ctx context.Context, userRequest *grpcHn.UserInfoRequest) (*grpcHn.User, error){
 	if userRequest.GetName() == "" {
 		return nil, status.Error(codes.InvalidArgument, "user nickname must be provided to fetch user details")
 	}
 
-	user, err := s.UserService.GetUserInfo(userRequest.GetName())
+	user, err := s.UserService.GetUserInfo(ctx, userRequest.GetName())
 	
-	if err != nil {
+	if err != nil && ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	} else if status.Code(err) == codes.Unavailable {
+		return nil, err
+	} else if err != nil {
 		return nil, status.Errorf(codes.Internal, "could not get user information. Caused by: %s", err.Error())
 	} else if user == nil {
 		return nil, status.Errorf(codes.NotFound, "user '%s' not found", userRequest.Name)
@@ -67,4 +272,97 @@ func (s *hackernewsProxyServer) Whois(_ context.Context, userRequest *grpcHn.Use
 		Karma: user.Karma,
 		JoinedAt: int64(user.Joined.Unix()),
 	}, nil	
-}
\ No newline at end of file
+}
+
+var storyLists = map[grpcHn.StoryListKind]sts.StoryList{
+	grpcHn.StoryListKind_TOP_STORIES: sts.TopStories,
+	grpcHn.StoryListKind_NEW_STORIES: sts.NewStories,
+	grpcHn.StoryListKind_BEST_STORIES: sts.BestStories,
+	grpcHn.StoryListKind_ASK_STORIES: sts.AskStories,
+	grpcHn.StoryListKind_SHOW_STORIES: sts.ShowStories,
+	grpcHn.StoryListKind_JOB_STORIES: sts.JobStories,
+}
+
+func mapStories(stories *[]sts.Story) []*grpcHn.Story {
+	var mappedStories = make([]*grpcHn.Story, len(*stories))
+
+	for i := range *stories {
+		mappedStories[i] = mapStory(&(*stories)[i])
+	}
+
+	return mappedStories
+}
+
+func mapStory(story *sts.Story) *grpcHn.Story {
+	return &grpcHn.Story {
+		Id: int64(story.Id),
+		Type: story.Type,
+		Title: story.Title,
+		Url: story.Url,
+		By: story.By,
+		CreatedAt: story.Time.Unix(),
+		Score: int64(story.Score),
+		Descendants: int64(story.Descendants),
+		Text: story.Text,
+		Kids: mapIds(story.Kids),
+	}
+}
+
+var frontPageEventKinds = map[frontpage.EventKind]grpcHn.FrontPageEventKind{
+	frontpage.StoryEntered: grpcHn.FrontPageEventKind_STORY_ENTERED,
+	frontpage.StoryLeft: grpcHn.FrontPageEventKind_STORY_LEFT,
+	frontpage.StoryMoved: grpcHn.FrontPageEventKind_STORY_MOVED,
+	frontpage.ScoreChanged: grpcHn.FrontPageEventKind_SCORE_CHANGED,
+}
+
+func mapFrontPageEvent(event frontpage.Event) *grpcHn.FrontPageEvent {
+	return &grpcHn.FrontPageEvent{
+		Kind: frontPageEventKinds[event.Kind],
+		Story: mapStory(&event.Story),
+		Rank: uint32(event.Rank),
+		PreviousRank: uint32(event.PreviousRank),
+		PreviousScore: int64(event.PreviousScore),
+	}
+}
+
+func mapItem(item *sts.Story) *grpcHn.Item {
+	return &grpcHn.Item{
+		Id: int64(item.Id),
+		Type: item.Type,
+		By: item.By,
+		CreatedAt: item.Time.Unix(),
+		Title: item.Title,
+		Url: item.Url,
+		Text: item.Text,
+		Score: int64(item.Score),
+		Descendants: int64(item.Descendants),
+		Parent: int64(item.Parent),
+		Kids: mapIds(item.Kids),
+		Parts: mapIds(item.Parts),
+		Dead: item.Dead,
+		Deleted: item.Deleted,
+	}
+}
+
+func mapCommentTree(tree *sts.CommentTree) *grpcHn.CommentTree {
+	var mappedChildren = make([]*grpcHn.CommentTree, len(tree.Children))
+
+	for i := range tree.Children {
+		mappedChildren[i] = mapCommentTree(&tree.Children[i])
+	}
+
+	return &grpcHn.CommentTree{
+		Item: mapItem(&tree.Item),
+		Children: mappedChildren,
+	}
+}
+
+func mapIds(ids []int) []int64 {
+	var mappedIds = make([]int64, len(ids))
+
+	for i, id := range ids {
+		mappedIds[i] = int64(id)
+	}
+
+	return mappedIds
+}
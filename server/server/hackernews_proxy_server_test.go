@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	grpcHn "hackernews/generated"
+
+	sts "hackernews/server/stories"
+	us "hackernews/server/users"
+)
+
+const maxStoriesPerRequest uint32 = 500
+
+type MockStoriesService struct {
+	sts.StoriesService
+	MockedGetStories func(list sts.StoryList, maxStoryCount uint32) (*sts.StoriesPage, error)
+	MockedGetStoriesPage func(list sts.StoryList, pageRequest sts.PageRequest) (*sts.StoriesPage, error)
+	MockedGetItem func(id int) (*sts.Story, error)
+	MockedGetCommentTree func(id int, maxDepth uint32, maxChildren uint32) (*sts.CommentTree, error)
+}
+
+func (m MockStoriesService) GetStories(_ context.Context, list sts.StoryList, maxStoryCount uint32) (*sts.StoriesPage, error) {
+	return m.MockedGetStories(list, maxStoryCount)
+}
+
+func (m MockStoriesService) GetStoriesPage(_ context.Context, list sts.StoryList, pageRequest sts.PageRequest) (*sts.StoriesPage, error) {
+	return m.MockedGetStoriesPage(list, pageRequest)
+}
+
+func (m MockStoriesService) GetItem(_ context.Context, id int) (*sts.Story, error) {
+	return m.MockedGetItem(id)
+}
+
+func (m MockStoriesService) GetCommentTree(_ context.Context, id int, maxDepth uint32, maxChildren uint32) (*sts.CommentTree, error) {
+	return m.MockedGetCommentTree(id, maxDepth, maxChildren)
+}
+
+type MockUserService struct {
+	MockedGetUserInfo func(nickname string) (*us.User, error)
+}
+
+func (m MockUserService) GetUserInfo(_ context.Context, nickname string) (*us.User, error) {
+	return m.MockedGetUserInfo(nickname)
+}
+
+func TestGetTopStoriesShouldValidateRequestedStoryCount(t *testing.T) {
+	availableStories := []sts.Story{{Id: 1}, {Id: 2}}
+
+	testCases := []struct{
+		name string
+		request *grpcHn.TopStoriesRequest
+		expectedCode codes.Code
+	}{
+		{name: "no story requested", request: &grpcHn.TopStoriesRequest{StoryNumber: 0}, expectedCode: codes.InvalidArgument},
+		{name: "stories within limit", request: &grpcHn.TopStoriesRequest{StoryNumber: 10}, expectedCode: codes.OK},
+		{name: "stories at limit", request: &grpcHn.TopStoriesRequest{StoryNumber: maxStoriesPerRequest}, expectedCode: codes.OK},
+		{name: "stories beyond limit", request: &grpcHn.TopStoriesRequest{StoryNumber: maxStoriesPerRequest + 1}, expectedCode: codes.InvalidArgument},
+		{name: "page within limit", request: &grpcHn.TopStoriesRequest{PageSize: 10}, expectedCode: codes.OK},
+		{name: "page beyond limit", request: &grpcHn.TopStoriesRequest{PageSize: maxStoriesPerRequest + 1}, expectedCode: codes.InvalidArgument},
+		{name: "page token without page size", request: &grpcHn.TopStoriesRequest{PageToken: "token"}, expectedCode: codes.InvalidArgument},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// GIVEN
+			storiesService := MockStoriesService{}
+			storiesService.MockedGetStories = func(list sts.StoryList, maxStoryCount uint32) (*sts.StoriesPage, error) {
+				return &sts.StoriesPage{Stories: availableStories, AvailableCount: len(availableStories)}, nil
+			}
+			storiesService.MockedGetStoriesPage = func(list sts.StoryList, pageRequest sts.PageRequest) (*sts.StoriesPage, error) {
+				return &sts.StoriesPage{Stories: availableStories, AvailableCount: len(availableStories)}, nil
+			}
+
+			server := NewHnProxyServer(storiesService, nil, nil, maxStoriesPerRequest)
+
+			// WHEN
+			topStories, err := server.GetTopStories(context.Background(), testCase.request)
+
+			// THEN
+			if status.Code(err) != testCase.expectedCode {
+				t.Fatalf("expected code '%s' but got error: %v", testCase.expectedCode, err)
+			}
+
+			if testCase.expectedCode == codes.OK && topStories.GetAvailableStoryCount() != uint32(len(availableStories)) {
+				t.Errorf("expected %d available stories but got %d", len(availableStories), topStories.GetAvailableStoryCount())
+			}
+		})
+	}
+}
+
+func TestStreamTopStoriesShouldValidateRequestedStoryCount(t *testing.T) {
+	testCases := []struct{
+		name string
+		storyNumber uint32
+	}{
+		{name: "no story requested", storyNumber: 0},
+		{name: "stories beyond limit", storyNumber: maxStoriesPerRequest + 1},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// GIVEN
+			server := NewHnProxyServer(MockStoriesService{}, nil, nil, maxStoriesPerRequest)
+
+			// WHEN
+			err := server.StreamTopStories(&grpcHn.TopStoriesRequest{StoryNumber: testCase.storyNumber}, nil)
+
+			// THEN
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("expected invalid argument but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestItemAndUserRpcsShouldReportMissingResourcesAsNotFound(t *testing.T) {
+	// GIVEN
+	storiesService := MockStoriesService{}
+	storiesService.MockedGetItem = func(id int) (*sts.Story, error) {
+		return nil, nil
+	}
+	storiesService.MockedGetCommentTree = func(id int, maxDepth uint32, maxChildren uint32) (*sts.CommentTree, error) {
+		return nil, nil
+	}
+
+	userService := MockUserService{}
+	userService.MockedGetUserInfo = func(nickname string) (*us.User, error) {
+		return nil, nil
+	}
+
+	server := NewHnProxyServer(storiesService, userService, nil, maxStoriesPerRequest)
+
+	// WHEN
+	_, itemErr := server.GetItem(context.Background(), &grpcHn.ItemRequest{Id: 1})
+	_, treeErr := server.GetCommentTree(context.Background(), &grpcHn.CommentTreeRequest{Id: 1, MaxDepth: 1, MaxChildren: 1})
+	_, userErr := server.Whois(context.Background(), &grpcHn.UserInfoRequest{Name: "pg"})
+
+	// THEN
+	for rpc, err := range map[string]error{"GetItem": itemErr, "GetCommentTree": treeErr, "Whois": userErr} {
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("%s should report missing resource as not found but got: %v", rpc, err)
+		}
+	}
+}
+
+func TestRpcsShouldReportRequestsGivenUpByClientAsSuch(t *testing.T) {
+	// GIVEN
+	storiesService := MockStoriesService{}
+	storiesService.MockedGetItem = func(id int) (*sts.Story, error) {
+		return nil, context.DeadlineExceeded
+	}
+
+	userService := MockUserService{}
+	userService.MockedGetUserInfo = func(nickname string) (*us.User, error) {
+		return nil, context.Canceled
+	}
+
+	server := NewHnProxyServer(storiesService, userService, nil, maxStoriesPerRequest)
+
+	expiredCtx, cancelExpired := context.WithDeadline(context.Background(), time.Now())
+	defer cancelExpired()
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// WHEN
+	_, itemErr := server.GetItem(expiredCtx, &grpcHn.ItemRequest{Id: 1})
+	_, userErr := server.Whois(cancelledCtx, &grpcHn.UserInfoRequest{Name: "pg"})
+
+	// THEN
+	if status.Code(itemErr) != codes.DeadlineExceeded {
+		t.Errorf("request past its deadline should fail with DeadlineExceeded but got: %v", itemErr)
+	}
+
+	if status.Code(userErr) != codes.Canceled {
+		t.Errorf("cancelled request should fail with Canceled but got: %v", userErr)
+	}
+}
+
+func TestRpcsShouldReportUnavailableUpstreamAsSuch(t *testing.T) {
+	// GIVEN
+	unavailable := status.Error(codes.Unavailable, "HackerNews API circuit breaker is open")
+
+	storiesService := MockStoriesService{}
+	storiesService.MockedGetStories = func(list sts.StoryList, maxStoryCount uint32) (*sts.StoriesPage, error) {
+		return nil, unavailable
+	}
+	storiesService.MockedGetItem = func(id int) (*sts.Story, error) {
+		return nil, unavailable
+	}
+
+	userService := MockUserService{}
+	userService.MockedGetUserInfo = func(nickname string) (*us.User, error) {
+		return nil, unavailable
+	}
+
+	server := NewHnProxyServer(storiesService, userService, nil, maxStoriesPerRequest)
+
+	// WHEN
+	_, storiesErr := server.GetTopStories(context.Background(), &grpcHn.TopStoriesRequest{StoryNumber: 10})
+	_, itemErr := server.GetItem(context.Background(), &grpcHn.ItemRequest{Id: 1})
+	_, userErr := server.Whois(context.Background(), &grpcHn.UserInfoRequest{Name: "pg"})
+
+	// THEN
+	for rpc, err := range map[string]error{"GetTopStories": storiesErr, "GetItem": itemErr, "Whois": userErr} {
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("%s should report HackerNews API as unavailable but got: %v", rpc, err)
+		}
+	}
+}
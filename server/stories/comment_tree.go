@@ -0,0 +1,7 @@
+package stories
+
+// Item along with its resolved children. The root is usually a story and its children are comments
+type CommentTree struct {
+	Item Story;
+	Children []CommentTree;
+}
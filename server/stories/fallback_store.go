@@ -0,0 +1,58 @@
+package stories
+
+import (
+	"sync"
+	"time"
+
+	"hackernews/server/cache"
+)
+
+// How long fetched stories are kept to be served when HackerNews API cannot be reached, well beyond their time to live in cache
+const fallbackTimeToLive = time.Hour
+const fallbackMaxStories = 10000
+
+// Last data successfully fetched from HackerNews API: the last ranking of each story list,
+// and stories for fallbackTimeToLive even once they expired from cache
+type fallbackStore struct {
+	mutex sync.Mutex
+	rankings map[StoryList]*Ranking
+	stories cache.Cache[int, fetched[*Story]]
+}
+
+type fetched[V any] struct {
+	value V
+	fetchedAt time.Time
+}
+
+func newFallbackStore() *fallbackStore {
+	return &fallbackStore{
+		rankings: make(map[StoryList]*Ranking),
+		stories: cache.NewLruTimeToLiveCache[int, fetched[*Story]](fallbackTimeToLive, fallbackMaxStories),
+	}
+}
+
+func (store *fallbackStore) saveRanking(list StoryList, ranking *Ranking) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.rankings[list] = ranking
+}
+
+// Last ranking fetched of list
+func (store *fallbackStore) getRanking(list StoryList) (*Ranking, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	ranking, isStored := store.rankings[list]
+	return ranking, isStored
+}
+
+func (store *fallbackStore) saveStory(id int, story *Story) {
+	store.stories.Add(id, fetched[*Story]{value: story, fetchedAt: time.Now()})
+}
+
+// Last version fetched of story, along with its fetch time
+func (store *fallbackStore) getStory(id int) (*Story, time.Time, bool) {
+	story, isStored := store.stories.Get(id)
+	return story.value, story.fetchedAt, isStored
+}
@@ -1,63 +1,539 @@
 package stories
 
 import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"hackernews/server/cache"
+	"hackernews/server/upstream"
 
-	hn "github.com/peterhellberg/hn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+var tracer = otel.Tracer("hackernews/server/stories")
+
+// How long page tokens can be used after the first page of a list has been fetched
+const rankingSnapshotTimeToLive = 10 * time.Minute
+// Snapshots are shared by the requests reading a same ranking, so few of them are alive at once
+const maxRankingSnapshots = 1000
+
 type hackernewsStoriesProxy struct {
-	hnClient hn.Client
+	hnClient upstream.Client
 	cache cache.Cache[int, *Story]
+	rankings cache.Cache[StoryList, *Ranking]
+	snapshots cache.Cache[string, *rankingSnapshot]
+	// Ids of the snapshots alive, by key of the ranking they were taken from
+	snapshotIds cache.Cache[string, string]
+	fallback *fallbackStore
+	maxParallelFetches int
 }
 
-func NewHackernewsStoriesProxy(client hn.Client, cache cache.Cache[int, *Story]) (StoriesService) {
+// Result of a story fetch, along with the rank of the story in the fetched list
+type rankedStory struct {
+	rank int
+	story *Story
+	// zero unless story was served from fallback store
+	staleSince time.Time
+	err error
+}
+
+// Rankings of story lists are read from rankingsCache, HackerNews API being requested only when they expired from it.
+// maxParallelFetches bounds the number of stories fetched at the same time from HackerNews
+func NewHackernewsStoriesProxy(client upstream.Client, storiesCache cache.Cache[int, *Story], rankingsCache cache.Cache[StoryList, *Ranking], maxParallelFetches uint32) (StoriesService) {
 	return &hackernewsStoriesProxy{
 		hnClient: client,
-		cache: cache,
+		cache: storiesCache,
+		rankings: rankingsCache,
+		snapshots: cache.NewLruTimeToLiveCache[string, *rankingSnapshot](rankingSnapshotTimeToLive, maxRankingSnapshots),
+		snapshotIds: cache.NewLruTimeToLiveCache[string, string](rankingSnapshotTimeToLive, maxRankingSnapshots),
+		fallback: newFallbackStore(),
+		maxParallelFetches: max(1, int(maxParallelFetches)),
 	}
 }
 
-func (hsp *hackernewsStoriesProxy) GetTopStories(maxStoryCount uint32) (*[]Story, error) {
-	idsStories, err := hsp.hnClient.TopStories()
+func (hsp *hackernewsStoriesProxy) GetTopStories(ctx context.Context, maxStoryCount uint32) (*[]Story, error) {
+	page, err := hsp.GetStories(ctx, TopStories, maxStoryCount)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "error occurred during top stories fetch. Cause: %v", err)
+		return nil, err
 	}
 
-	var stories = make([]Story, maxStoryCount)
+	return &page.Stories, nil
+}
 
-	for i, storyId := range idsStories[:maxStoryCount] {
-		storyFromCache, storyIsCached  := hsp.cache.Get(storyId)
+// Fetches first nth stories of list. Fewer stories are returned if HackerNews does not have as many in the list.
+// When HackerNews API fails, the last list and stories fetched are served instead, and the page is flagged as stale
+func (hsp *hackernewsStoriesProxy) GetStories(ctx context.Context, list StoryList, maxStoryCount uint32) (*StoriesPage, error) {
+	if _, listExists := list.path(); !listExists {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown story list '%d'", list)
+	}
 
-		if storyIsCached {
-			stories[i] = *storyFromCache
-		} else {
-			story, err := hsp.fetchStory(storyId)
+	ranking, rankingStaleSince, err := hsp.getRanking(ctx, list)
+	if err != nil {
+		return nil, upstream.ErrorStatus(err, "error occurred during stories fetch. Cause: %v", err)
+	}
+
+	stories, storiesStaleSince, err := hsp.getStories(ctx, firstIds(ranking.Ids, maxStoryCount), true)
+	if err != nil {
+		return nil, upstream.ErrorStatus(err, "error encountered while fetching stories. Cause: %v", err)
+	}
+
+	return &StoriesPage{
+		Stories: stories,
+		AvailableCount: len(ranking.Ids),
+		Freshness: freshness(ranking.FetchedAt, oldestFetch(rankingStaleSince, storiesStaleSince)),
+	}, nil
+}
+
+// Fetches a page of a story list. Fetching a page without token takes a snapshot of the ranking,
+// which the following pages requested through page tokens stick to even if the list has been reshuffled in between
+func (hsp *hackernewsStoriesProxy) GetStoriesPage(ctx context.Context, list StoryList, pageRequest PageRequest) (*StoriesPage, error) {
+	if _, listExists := list.path(); !listExists {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown story list '%d'", list)
+	}
+
+	snapshotId, snapshot, offset, err := hsp.getRankingSnapshot(ctx, list, pageRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	start := min(offset, len(snapshot.ids))
+	end := min(start + int(pageRequest.PageSize), len(snapshot.ids))
+
+	stories, storiesStaleSince, err := hsp.getStories(ctx, snapshot.ids[start:end], true)
+	if err != nil {
+		return nil, upstream.ErrorStatus(err, "error encountered while fetching stories. Cause: %v", err)
+	}
+
+	page := StoriesPage{
+		Stories: stories,
+		AvailableCount: len(snapshot.ids),
+		Freshness: freshness(snapshot.fetchedAt, oldestFetch(snapshot.staleSince, storiesStaleSince)),
+	}
+
+	if end < len(snapshot.ids) {
+		hsp.snapshots.Add(snapshotId, snapshot)
+		hsp.snapshotIds.Add(rankingKeyOf(list, snapshot.ids), snapshotId)
+		page.NextPageToken = pageToken{snapshotId: snapshotId, offset: end}.encode()
+	}
+
+	return &page, nil
+}
+
+// Gets the snapshot a page token points to, or takes a new one when no token is provided.
+// Also returns the offset of the requested page within the snapshot
+func (hsp *hackernewsStoriesProxy) getRankingSnapshot(ctx context.Context, list StoryList, pageRequest PageRequest) (string, *rankingSnapshot, int, error) {
+	if pageRequest.PageToken == "" {
+		ranking, staleSince, err := hsp.getRanking(ctx, list)
+		if err != nil {
+			return "", nil, 0, upstream.ErrorStatus(err, "error occurred during stories fetch. Cause: %v", err)
+		}
+
+		snapshot := &rankingSnapshot{list: list, ids: ranking.Ids, fetchedAt: ranking.FetchedAt, staleSince: staleSince}
+		return hsp.snapshotIdOf(snapshot), snapshot, int(pageRequest.Offset), nil
+	}
+
+	token, err := decodePageToken(pageRequest.PageToken)
+	if err != nil {
+		return "", nil, 0, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	snapshot, snapshotExists := hsp.snapshots.Get(token.snapshotId)
+	if !snapshotExists {
+		return "", nil, 0, status.Error(codes.InvalidArgument, "page token expired, first page must be fetched again")
+	} else if snapshot.list != list {
+		return "", nil, 0, status.Error(codes.InvalidArgument, "page token was issued for another story list")
+	}
+
+	return token.snapshotId, snapshot, token.offset, nil
+}
+
+// Id of the snapshot already taken from the same ranking if it is still alive, so that it is shared, or a new random id
+func (hsp *hackernewsStoriesProxy) snapshotIdOf(snapshot *rankingSnapshot) string {
+	if snapshotId, isKnown := hsp.snapshotIds.Get(rankingKeyOf(snapshot.list, snapshot.ids)); isKnown {
+		if shared, isAlive := hsp.snapshots.Get(snapshotId); isAlive && slices.Equal(shared.ids, snapshot.ids) {
+			return snapshotId
+		}
+	}
+
+	return newSnapshotId()
+}
+
+// Hands each top story to onStory as soon as it is available, without waiting for the slower fetches.
+// Stories are not handed in rank order. Streaming stops at the first error returned by onStory.
+// Fewer stories are handed if HackerNews does not have as many top stories.
+// When HackerNews API fails, the last list and stories fetched are handed instead, as told by the returned freshness
+func (hsp *hackernewsStoriesProxy) StreamTopStories(ctx context.Context, maxStoryCount uint32, onStory func(rank int, story *Story) error) (Freshness, error) {
+	ranking, rankingStaleSince, err := hsp.getRanking(ctx, TopStories)
+	if err != nil {
+		return Freshness{}, upstream.ErrorStatus(err, "error occurred during top stories fetch. Cause: %v", err)
+	}
+
+	storiesStaleSince, err := hsp.streamStories(ctx, firstIds(ranking.Ids, maxStoryCount), true, onStory)
+	if err != nil {
+		return Freshness{}, err
+	}
+
+	return freshness(ranking.FetchedAt, oldestFetch(rankingStaleSince, storiesStaleSince)), nil
+}
+
+// Fetches the ranking of list from HackerNews API and caches it, so that the following requests read it from cache
+func (hsp *hackernewsStoriesProxy) RefreshRanking(ctx context.Context, list StoryList) error {
+	if _, listExists := list.path(); !listExists {
+		return status.Errorf(codes.InvalidArgument, "unknown story list '%d'", list)
+	}
+
+	ranking, err := hsp.fetchRanking(ctx, list)
+	if err != nil {
+		return upstream.ErrorStatus(err, "error occurred during stories fetch. Cause: %v", err)
+	}
+
+	hsp.rankings.Add(list, ranking)
+	return nil
+}
+
+// Gets any item (story, comment, job...) from its id. Returns nil if the item does not exist
+func (hsp *hackernewsStoriesProxy) GetItem(ctx context.Context, id int) (*Story, error) {
+	ctx, span := tracer.Start(ctx, "stories.LookupItem", trace.WithAttributes(attribute.Int("hackernews.item.id", id)))
+	defer span.End()
+
+	var fetched atomic.Bool
+	item, err := hsp.cache.GetOrLoad(ctx, id, func(ctx context.Context) (*Story, error) {
+		fetched.Store(true)
+		return hsp.fetchStory(ctx, id)
+	})
+	span.SetAttributes(attribute.Bool("hackernews.cache.hit", !fetched.Load()))
+
+	if err != nil {
+		return nil, err
+	} else if item == nil || itemNotFound(item) {
+		return nil, nil
+	}
+
+	return item, nil
+}
+
+// Resolves the discussion under item, down to maxDepth levels of comments and keeping at most maxChildren comments per item.
+// Comments of a same level are fetched concurrently. Deleted and dead comments are skipped, without counting toward maxChildren.
+// Returns nil if the item does not exist
+func (hsp *hackernewsStoriesProxy) GetCommentTree(ctx context.Context, id int, maxDepth uint32, maxChildren uint32) (*CommentTree, error) {
+	root, err := hsp.GetItem(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if root == nil {
+		return nil, nil
+	}
+
+	tree := &CommentTree{Item: *root}
+	level := []*CommentTree{tree}
+
+	for depth := uint32(0); depth < maxDepth && len(level) > 0; depth++ {
+		if err := hsp.resolveChildren(ctx, level, int(maxChildren)); err != nil {
+			return nil, upstream.ErrorStatus(err, "error encountered while fetching comments of item '%d'. Cause: %v", id, err)
+		}
+
+		var nextLevel []*CommentTree
+		for _, node := range level {
+			for i := range node.Children {
+				nextLevel = append(nextLevel, &node.Children[i])
+			}
+		}
+
+		level = nextLevel
+	}
+
+	return tree, nil
+}
 
-			if err != nil {
-				return nil, status.Errorf(codes.Internal, "error encountered while fetching top stories. Cause: %v", err)
+// Resolves up to maxChildren live children of each node of level. Kids are fetched as many as children are missing,
+// skipped comments being replaced by the following kids of their parent in another round, until every node is complete
+// or has no kid left. Each round fetches the kids of the whole level concurrently
+func (hsp *hackernewsStoriesProxy) resolveChildren(ctx context.Context, level []*CommentTree, maxChildren int) error {
+	nextKids := make([]int, len(level))
+	requestedCounts := make([]int, len(level))
+
+	for {
+		var childrenIds []int
+		for i, node := range level {
+			missingCount := maxChildren - len(node.Children)
+			requested := node.Item.Kids[nextKids[i]:min(len(node.Item.Kids), nextKids[i] + max(0, missingCount))]
+
+			requestedCounts[i] = len(requested)
+			childrenIds = append(childrenIds, requested...)
+		}
+
+		if len(childrenIds) == 0 {
+			return nil
+		}
+
+		children, _, err := hsp.getStories(ctx, childrenIds, false)
+		if err != nil {
+			return err
+		}
+
+		for i, node := range level {
+			for _, child := range children[:requestedCounts[i]] {
+				if !child.Deleted && !child.Dead && !itemNotFound(&child) {
+					node.Children = append(node.Children, CommentTree{Item: child})
+				}
 			}
+			children = children[requestedCounts[i]:]
+			nextKids[i] += requestedCounts[i]
+		}
+	}
+}
+
+// First ids of a ranking, bounded by the number of ids actually in it
+func firstIds(ids []int, count uint32) []int {
+	return ids[:min(len(ids), int(count))]
+}
+
+// Fetches the ranking of list, and keeps it in fallback store.
+// Only top stories are exposed by the live service, other lists are requested directly to HackerNews API.
+func (hsp *hackernewsStoriesProxy) fetchRanking(ctx context.Context, list StoryList) (*Ranking, error) {
+	path, _ := list.path()
+	ctx, span := tracer.Start(ctx, "hn.StoryIds", trace.WithAttributes(attribute.String("hackernews.list", path)))
+	defer span.End()
+
+	var ids []int
+	var err error
+
+	if list == TopStories {
+		ids, err = hsp.hnClient.Live.TopStories(ctx)
+	} else {
+		ids, err = hsp.hnClient.Live.StoryIds(ctx, path)
+	}
+
+	if err != nil {
+		span.SetStatus(otelcodes.Error, err.Error())
+		return nil, err
+	}
+
+	ranking := &Ranking{Ids: ids, FetchedAt: time.Now()}
+	hsp.fallback.saveRanking(list, ranking)
+
+	return ranking, nil
+}
+
+// Gets the ranking of list from cache, or fetches and caches it. Concurrent misses of a same list share a single fetch.
+// Falls back to the last ranking fetched when HackerNews API fails, in which case its fetch time is also returned,
+// zero when the ranking is fresh
+func (hsp *hackernewsStoriesProxy) getRanking(ctx context.Context, list StoryList) (*Ranking, time.Time, error) {
+	ranking, err := hsp.rankings.GetOrLoad(ctx, list, func(ctx context.Context) (*Ranking, error) {
+		return hsp.fetchRanking(ctx, list)
+	})
+	if err == nil || ctx.Err() != nil {
+		return ranking, time.Time{}, err
+	}
+
+	if lastRanking, isStored := hsp.fallback.getRanking(list); isStored {
+		return lastRanking, lastRanking.FetchedAt, nil
+	}
+
+	return nil, time.Time{}, err
+}
+
+// Gets stories from cache, and fetches the missing ones through a bounded pool of workers.
+// Stories are returned in the same order as ids. The first failure cancels the remaining fetches.
+// See streamStories for withFallback and the returned fetch time
+func (hsp *hackernewsStoriesProxy) getStories(ctx context.Context, ids []int, withFallback bool) ([]Story, time.Time, error) {
+	var stories = make([]Story, len(ids))
+
+	staleSince, err := hsp.streamStories(ctx, ids, withFallback, func(rank int, story *Story) error {
+		stories[rank] = *story
+		return nil
+	})
+
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return stories, staleSince, nil
+}
+
+// Hands stories to onStory, along with their rank in ids, as soon as they are available:
+// cached stories first, then missing ones in the order their fetches complete.
+// The first failure, either from a fetch or from onStory, cancels the remaining fetches.
+// withFallback hands the last version fetched of stories whose fetch fails instead, in which case the fetch time
+// of the oldest of them is returned. It is zero when every story was fresh
+func (hsp *hackernewsStoriesProxy) streamStories(ctx context.Context, ids []int, withFallback bool, onStory func(rank int, story *Story) error) (time.Time, error) {
+	ctx, span := tracer.Start(ctx, "stories.Lookup", trace.WithAttributes(attribute.Int("hackernews.stories.count", len(ids))))
+	defer span.End()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var missingRanks []int
+
+	for rank, id := range ids {
+		storyFromCache, storyIsCached := hsp.getCachedItem(ctx, id)
+
+		if !storyIsCached || storyFromCache == nil {
+			missingRanks = append(missingRanks, rank)
+		} else if err := onStory(rank, storyFromCache); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("hackernews.cache.hits", len(ids) - len(missingRanks)),
+		attribute.Int("hackernews.cache.misses", len(missingRanks)),
+	)
 
-			stories[i] = *story
-			hsp.cache.Add(storyId, story)
+	var firstErr error
+	var staleSince time.Time
+	receivedCount := 0
+
+	for result := range hsp.fetchStories(ctx, ids, missingRanks, withFallback) {
+		receivedCount++
+		if firstErr != nil {
+			continue
 		}
+
+		if result.err != nil {
+			firstErr = result.err
+		} else {
+			staleSince = oldestFetch(staleSince, result.staleSince)
+			firstErr = onStory(result.rank, result.story)
+		}
+
+		if firstErr != nil {
+			cancel()
+		}
+	}
+
+	// stories left unfetched because ctx was done before any failure
+	if firstErr == nil && receivedCount < len(missingRanks) {
+		return time.Time{}, ctx.Err()
+	} else if firstErr != nil {
+		return time.Time{}, firstErr
 	}
 
-	return &stories, nil
+	return staleSince, nil
 }
 
-func (hsp *hackernewsStoriesProxy) fetchStory(id int) (*Story, error) {
-	rawStory, err := hsp.hnClient.Item(id)
-	
+// Fetches concurrently the stories at given ranks and adds them to cache.
+// Stories already being fetched by another request are awaited rather than fetched again.
+// Results are sent as soon as they are fetched, so they are not ordered, and must all be received.
+// Once a fetch fails, its error is sent and no other story is fetched, unless withFallback finds the last version fetched of the story.
+func (hsp *hackernewsStoriesProxy) fetchStories(ctx context.Context, ids []int, ranks []int, withFallback bool) <-chan rankedStory {
+	ctx, cancel := context.WithCancel(ctx)
+
+	ranksToFetch := make(chan int)
+	results := make(chan rankedStory)
+
+	go func() {
+		defer close(ranksToFetch)
+
+		for _, rank := range ranks {
+			select {
+			case ranksToFetch <- rank:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	workersCount := min(hsp.maxParallelFetches, len(ranks))
+	var workers sync.WaitGroup
+	workers.Add(workersCount)
+
+	for range workersCount {
+		go func() {
+			defer workers.Done()
+
+			for rank := range ranksToFetch {
+				if ctx.Err() != nil {
+					continue
+				}
+
+				story, err := hsp.loadStory(ctx, ids[rank])
+				if err != nil && withFallback && ctx.Err() == nil {
+					if lastStory, fetchedAt, isStored := hsp.fallback.getStory(ids[rank]); isStored {
+						results <- rankedStory{rank: rank, story: lastStory, staleSince: fetchedAt}
+						continue
+					}
+				}
+
+				if err != nil {
+					cancel()
+					results <- rankedStory{rank: rank, err: err}
+					continue
+				}
+
+				results <- rankedStory{rank: rank, story: story}
+			}
+		}()
+	}
+
+	go func() {
+		workers.Wait()
+		cancel()
+		close(results)
+	}()
+
+	return results
+}
+
+// Gets item from cache, refreshing it in background if it is stale. The refresh is not aborted once ctx is cancelled,
+// but gives up at its deadline
+func (hsp *hackernewsStoriesProxy) getCachedItem(ctx context.Context, id int) (*Story, bool) {
+	return hsp.cache.GetOrRevalidate(id, func() (*Story, error) {
+		refreshCtx, cancel := cache.Detach(ctx)
+		defer cancel()
+
+		return hsp.fetchStory(refreshCtx, id)
+	})
+}
+
+// Fetches and caches item, once getCachedItem missed it. Concurrent loads of a same item share a single fetch,
+// traced within the request which started it and aborted once no request waits for it anymore
+func (hsp *hackernewsStoriesProxy) loadStory(ctx context.Context, id int) (*Story, error) {
+	return hsp.cache.Load(ctx, id, func(ctx context.Context) (*Story, error) {
+		return hsp.fetchStory(ctx, id)
+	})
+}
+
+func (hsp *hackernewsStoriesProxy) fetchStory(ctx context.Context, id int) (*Story, error) {
+	ctx, span := tracer.Start(ctx, "hn.Item", trace.WithAttributes(attribute.Int("hackernews.item.id", id)))
+	defer span.End()
+
+	rawStory, err := hsp.hnClient.Items.Get(ctx, id)
+
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "could not fetch story '%d'. Cause: %v", id, err)
-	}	
+		span.SetStatus(otelcodes.Error, err.Error())
+		return nil, upstream.ErrorStatus(err, "could not fetch story '%d'. Cause: %v", id, err)
+	}
 
-	return &Story{
+	story := &Story{
 		Id: rawStory.ID,
+		Type: rawStory.Type,
 		Title: rawStory.Title,
 		Url: rawStory.URL,
-	}, nil
+		Text: rawStory.Text,
+		By: rawStory.By,
+		Time: rawStory.Time(),
+		Score: rawStory.Score,
+		Descendants: rawStory.Descendants,
+		Parent: rawStory.Parent,
+		Kids: rawStory.Kids,
+		Parts: rawStory.Parts,
+		Dead: rawStory.Dead,
+		Deleted: rawStory.Deleted,
+	}
+	hsp.fallback.saveStory(id, story)
+
+	return story, nil
+}
+
+// HackerNews API answers null for unknown items, and every existing item has a type
+func itemNotFound(item *Story) bool {
+	return item.Type == ""
 }
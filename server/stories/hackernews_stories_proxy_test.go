@@ -1,39 +1,79 @@
 package stories
 
 import (
+	"context"
 	"errors"
 	"hackernews/server/cache"
+	"hackernews/server/upstream"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	hn "github.com/peterhellberg/hn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+const maxParallelFetches uint32 = 4
+
 type MockHnLiveService struct {
 	MockedTopStories func() ([]int, error)
-	MockedMaxItem func() (int, error)
-	MockedUpdates func() (*hn.Updates, error)
+	MockedStoryIds func(path string) ([]int, error)
 }
 
-func (m MockHnLiveService) TopStories() ([]int, error) {
+func (m MockHnLiveService) TopStories(_ context.Context) ([]int, error) {
 	return m.MockedTopStories()
 }
 
-func (m MockHnLiveService) MaxItem() (int, error) {
-	return m.MockedMaxItem()
-}
-
-func (m MockHnLiveService) Updates() (*hn.Updates, error) {
-	return m.MockedUpdates()
+func (m MockHnLiveService) StoryIds(_ context.Context, path string) ([]int, error) {
+	return m.MockedStoryIds(path)
 }
 
 type MockHnItemService struct {
 	MockedItem func(id int) (*hn.Item, error)
 }
 
-func (m MockHnItemService) Get(id int) (*hn.Item, error) {
+func (m MockHnItemService) Get(_ context.Context, id int) (*hn.Item, error) {
 	return m.MockedItem(id)
 }
 
+var spanExporter = tracetest.NewInMemoryExporter()
+var tracingSetUp sync.Once
+
+// Records spans of the stories package, which only binds to the first global tracer provider
+func recordSpans() *tracetest.InMemoryExporter {
+	tracingSetUp.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+	})
+	spanExporter.Reset()
+
+	return spanExporter
+}
+
+// Rankings cache whose entries expire right away, so that every request fetches its ranking
+func uncachedRankings() cache.Cache[StoryList, *Ranking] {
+	return cache.NewLruTimeToLiveCache[StoryList, *Ranking](0, len(storyListPaths))
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, keyValue := range span.Attributes {
+		if keyValue.Key == key {
+			return keyValue.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
 func TestGetTopStoriesReturnErrorIfTopStoriesFetchFails(t *testing.T) {
 	// GIVEN
 	mockLiveService := MockHnLiveService{}
@@ -41,12 +81,12 @@ func TestGetTopStoriesReturnErrorIfTopStoriesFetchFails(t *testing.T) {
 		return nil, errors.New("top stories fetch fail")
 	}
 
-	var client hn.Client = hn.Client{Live: mockLiveService}
-	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](10)
-	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache)
+	var client upstream.Client = upstream.Client{Live: mockLiveService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
 
 	// WHEN
-	_, err := service.GetTopStories(1)
+	_, err := service.GetTopStories(context.Background(), 1)
 
 	// THEN
 	if err == nil {
@@ -63,16 +103,16 @@ func TestGetTopStoriesShouldGetStoriesFromCache(t *testing.T) {
 		return topStories, nil
 	}
 
-	var client hn.Client = hn.Client{Live: mockLiveService}
-	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](10)
-	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache)
+	var client upstream.Client = upstream.Client{Live: mockLiveService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
 
 	for _, storyId := range topStories {
 		storiesCache.Add(storyId, &Story{Id: storyId})
 	}
 
 	// WHEN
-	stories, err := service.GetTopStories(uint32(len(topStories)))
+	stories, err := service.GetTopStories(context.Background(), uint32(len(topStories)))
 
 	// THEN
 	if err != nil {
@@ -106,12 +146,12 @@ func TestGetTopStoriesShouldFetchStories(t *testing.T) {
 		return &topStory, nil
 	}
 
-	var client hn.Client = hn.Client{Live: mockLiveService, Items: mockItemService}
-	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](10)
-	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache)
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
 
 	// WHEN
-	stories, err := service.GetTopStories(uint32(len(topStoriesIds)))
+	stories, err := service.GetTopStories(context.Background(), uint32(len(topStoriesIds)))
 
 	// THEN
 	if err != nil {
@@ -148,12 +188,12 @@ func TestGetTopStoriesShouldReturnErrorIfFetchStoryFails(t *testing.T) {
 		return nil, errors.New("item fetch fail")
 	}
 
-	var client hn.Client = hn.Client{Live: mockLiveService, Items: mockItemService}
-	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](10)
-	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache)
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
 
 	// WHEN
-	stories, err := service.GetTopStories(uint32(len(topStoriesIds)))
+	stories, err := service.GetTopStories(context.Background(), uint32(len(topStoriesIds)))
 
 	// THEN
 	if err == nil {
@@ -167,3 +207,1160 @@ func TestGetTopStoriesShouldReturnErrorIfFetchStoryFails(t *testing.T) {
 		t.Error("story should not have been cached because it couldn't be fetched")
 	}
 }
+
+
+func TestGetTopStoriesShouldKeepRankOrderWhenFetchingConcurrently(t *testing.T) {
+	// GIVEN
+	topStoriesIds := []int{5, 4, 3, 2, 1, 0}
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return topStoriesIds, nil
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		// last ranked stories resolve first
+		time.Sleep(time.Millisecond * time.Duration(5 - id))
+		return &hn.Item{ID: id}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), uint32(len(topStoriesIds)))
+
+	// WHEN
+	stories, err := service.GetTopStories(context.Background(), uint32(len(topStoriesIds)))
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	}
+
+	for i, expectedStoryId := range topStoriesIds {
+		actualStory := (*stories)[i]
+
+		if actualStory.Id != expectedStoryId {
+			t.Errorf("Found actual id '%d' at rank %d but expected '%d'", actualStory.Id, i, expectedStoryId)
+		}
+	}
+}
+
+func TestGetTopStoriesShouldNotExceedMaxParallelFetches(t *testing.T) {
+	// GIVEN
+	topStoriesIds := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	const parallelFetches = 3
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return topStoriesIds, nil
+	}
+
+	var mutex sync.Mutex
+	var inFlight, maxInFlight int
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		mutex.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mutex.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+
+		return &hn.Item{ID: id}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), parallelFetches)
+
+	// WHEN
+	_, err := service.GetTopStories(context.Background(), uint32(len(topStoriesIds)))
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	}
+
+	if maxInFlight > parallelFetches {
+		t.Errorf("expected at most %d parallel fetches but got %d", parallelFetches, maxInFlight)
+	}
+}
+
+func TestGetTopStoriesShouldStopFetchingAfterFirstFailure(t *testing.T) {
+	// GIVEN
+	topStoriesIds := []int{0, 1, 2, 3, 4}
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return topStoriesIds, nil
+	}
+
+	var fetchCount atomic.Int32
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		fetchCount.Add(1)
+		return nil, errors.New("item fetch fail")
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), 1)
+
+	// WHEN
+	_, err := service.GetTopStories(context.Background(), uint32(len(topStoriesIds)))
+
+	// THEN
+	if err == nil {
+		t.Error("should encounter an error on item fetch failure")
+	}
+
+	if fetchCount.Load() != 1 {
+		t.Errorf("expected fetches to stop after first failure but %d fetches were made", fetchCount.Load())
+	}
+}
+
+func TestGetStoriesShouldFetchRequestedListFromHn(t *testing.T) {
+	// GIVEN
+	newStoriesIds := []int{3, 2}
+
+	hnApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/newstories.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("[3, 2, 1]"))
+	}))
+	defer hnApi.Close()
+
+	baseUrl, _ := url.Parse(hnApi.URL + "/v0/")
+	client := upstream.NewClient(hnApi.Client(), baseUrl)
+	client.Items = MockHnItemService{MockedItem: func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id}, nil
+	}}
+
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	page, err := service.GetStories(context.Background(), NewStories, uint32(len(newStoriesIds)))
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if len(page.Stories) != len(newStoriesIds) {
+		t.Fatalf("expected %d stories but got %d", len(newStoriesIds), len(page.Stories))
+	} else if page.AvailableCount != 3 {
+		t.Errorf("expected 3 available stories but got %d", page.AvailableCount)
+	}
+
+	for i, expectedStoryId := range newStoriesIds {
+		if actualId := page.Stories[i].Id; actualId != expectedStoryId {
+			t.Errorf("Found actual id '%d' but expected '%d'", actualId, expectedStoryId)
+		}
+	}
+}
+
+func TestGetStoriesShouldReturnErrorIfListUnknown(t *testing.T) {
+	// GIVEN
+	var client upstream.Client = upstream.Client{}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	_, err := service.GetStories(context.Background(), StoryList(-1), 1)
+
+	// THEN
+	if err == nil {
+		t.Error("error should be raised if story list is unknown")
+	}
+}
+
+func TestGetItemShouldFetchEveryItemField(t *testing.T) {
+	// GIVEN
+	rawItem := hn.Item{
+		ID: 42,
+		Type: "story",
+		By: "antwan",
+		Timestamp: 1234,
+		Title: "title",
+		URL: "https://example.com",
+		Score: 100,
+		Descendants: 3,
+		Kids: []int{43, 44},
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &rawItem, nil
+	}
+
+	var client upstream.Client = upstream.Client{Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	item, err := service.GetItem(context.Background(), rawItem.ID)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if item == nil {
+		t.Fatal("item should exist")
+	}
+
+	if item.By != rawItem.By || item.Score != rawItem.Score || item.Descendants != rawItem.Descendants {
+		t.Errorf("item fields were not all mapped: %+v", *item)
+	} else if !item.Time.Equal(time.Unix(1234, 0)) {
+		t.Errorf("expected item time '%v' but got '%v'", time.Unix(1234, 0), item.Time)
+	} else if len(item.Kids) != len(rawItem.Kids) {
+		t.Errorf("expected %d kids but got %d", len(rawItem.Kids), len(item.Kids))
+	}
+
+	if _, itemIsCached := storiesCache.Get(rawItem.ID); !itemIsCached {
+		t.Error("item should have been added to cache")
+	}
+}
+
+func TestGetItemShouldReturnNilIfItemNotFound(t *testing.T) {
+	// GIVEN
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	item, err := service.GetItem(context.Background(), 42)
+
+	// THEN
+	if err != nil {
+		t.Errorf("no error should be met but got: %v", err)
+	} else if item != nil {
+		t.Error("item should not have been found")
+	}
+}
+
+func TestGetCommentTreeShouldResolveChildrenWithinLimits(t *testing.T) {
+	// GIVEN
+	items := map[int]hn.Item{
+		1: {ID: 1, Type: "story", Kids: []int{2, 3, 4, 7}},
+		2: {ID: 2, Type: "comment", Kids: []int{5}},
+		3: {ID: 3, Type: "comment", Deleted: true},
+		4: {ID: 4, Type: "comment"},
+		5: {ID: 5, Type: "comment", Kids: []int{6}},
+		6: {ID: 6, Type: "comment"},
+		7: {ID: 7, Type: "comment"},
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		item := items[id]
+		return &item, nil
+	}
+
+	var client upstream.Client = upstream.Client{Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	tree, err := service.GetCommentTree(context.Background(), 1, 2, 2)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	}
+
+	// comment 3 is deleted, comment 7 is beyond max children and comment 6 is beyond max depth
+	if len(tree.Children) != 2 {
+		t.Fatalf("expected 2 children for story but got %d", len(tree.Children))
+	} else if tree.Children[0].Item.Id != 2 || tree.Children[1].Item.Id != 4 {
+		t.Errorf("expected comments '2' and '4' as story children but got '%d' and '%d'", tree.Children[0].Item.Id, tree.Children[1].Item.Id)
+	}
+
+	grandChildren := tree.Children[0].Children
+	if len(grandChildren) != 1 || grandChildren[0].Item.Id != 5 {
+		t.Fatalf("expected comment '5' as only reply of comment '2' but got %+v", grandChildren)
+	} else if len(grandChildren[0].Children) != 0 {
+		t.Error("comments beyond max depth should not be resolved")
+	}
+}
+
+func TestGetCommentTreeShouldNotCountDeadAndDeletedCommentsTowardMaxChildren(t *testing.T) {
+	// GIVEN
+	items := map[int]hn.Item{
+		1: {ID: 1, Type: "story", Kids: []int{2, 3, 4, 5, 6, 7}},
+		2: {ID: 2, Type: "comment", Dead: true},
+		3: {ID: 3, Type: "comment", Deleted: true},
+		4: {ID: 4, Type: "comment"},
+		5: {ID: 5, Type: "comment", Dead: true},
+		6: {ID: 6, Type: "comment"},
+		7: {ID: 7, Type: "comment"},
+	}
+
+	var fetchCount atomic.Int32
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		fetchCount.Add(1)
+		item := items[id]
+		return &item, nil
+	}
+
+	var client upstream.Client = upstream.Client{Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	tree, err := service.GetCommentTree(context.Background(), 1, 1, 2)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	}
+
+	if len(tree.Children) != 2 || tree.Children[0].Item.Id != 4 || tree.Children[1].Item.Id != 6 {
+		t.Errorf("expected the first 2 live comments '4' and '6' as story children but got %+v", tree.Children)
+	}
+
+	// the story, then its first kids until 2 live ones were found
+	if fetchCount.Load() != 6 {
+		t.Errorf("comments after the last child kept should not be fetched, expected 6 fetches but got %d", fetchCount.Load())
+	}
+}
+
+func TestGetCommentTreeShouldReturnNilIfItemNotFound(t *testing.T) {
+	// GIVEN
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	tree, err := service.GetCommentTree(context.Background(), 1, 2, 2)
+
+	// THEN
+	if err != nil {
+		t.Errorf("no error should be met but got: %v", err)
+	} else if tree != nil {
+		t.Error("no tree should be returned for unknown item")
+	}
+}
+
+func TestStreamTopStoriesShouldHandCachedStoriesFirst(t *testing.T) {
+	// GIVEN
+	topStoriesIds := []int{0, 1, 2}
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return topStoriesIds, nil
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	storiesCache.Add(2, &Story{Id: 2})
+
+	// WHEN
+	var streamedRanks []int
+	_, err := service.StreamTopStories(context.Background(), uint32(len(topStoriesIds)), func(rank int, story *Story) error {
+		if story.Id != topStoriesIds[rank] {
+			t.Errorf("story '%d' streamed with rank %d", story.Id, rank)
+		}
+		streamedRanks = append(streamedRanks, rank)
+		return nil
+	})
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if len(streamedRanks) != len(topStoriesIds) {
+		t.Fatalf("expected %d streamed stories but got %d", len(topStoriesIds), len(streamedRanks))
+	} else if streamedRanks[0] != 2 {
+		t.Errorf("cached story should be streamed first but got rank %d", streamedRanks[0])
+	}
+}
+
+func TestStreamTopStoriesShouldStopWhenStoryCannotBeHanded(t *testing.T) {
+	// GIVEN
+	topStoriesIds := []int{0, 1, 2, 3}
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return topStoriesIds, nil
+	}
+
+	var fetchCount atomic.Int32
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		fetchCount.Add(1)
+		return &hn.Item{ID: id}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), 1)
+
+	// WHEN
+	_, err := service.StreamTopStories(context.Background(), uint32(len(topStoriesIds)), func(rank int, story *Story) error {
+		return errors.New("stream closed")
+	})
+
+	// THEN
+	if err == nil {
+		t.Error("error returned while handing story should be raised")
+	}
+
+	if fetchCount.Load() >= int32(len(topStoriesIds)) {
+		t.Errorf("fetches should stop once a story cannot be handed but %d fetches were made", fetchCount.Load())
+	}
+}
+
+func TestGetTopStoriesShouldRecordSingleCacheMissPerFetchedStory(t *testing.T) {
+	// GIVEN
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return []int{1, 2, 3}, nil
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id, Type: "story"}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	_, coldErr := service.GetTopStories(context.Background(), 3)
+	_, warmErr := service.GetTopStories(context.Background(), 3)
+
+	// THEN
+	if coldErr != nil || warmErr != nil {
+		t.Fatalf("no error should be met but got: %v, %v", coldErr, warmErr)
+	}
+
+	if stats := storiesCache.Stats(); stats.Misses != 3 || stats.Hits != 3 {
+		t.Errorf("expected 3 misses then 3 hits but got %+v", stats)
+	}
+}
+
+func TestGetStoriesPageShouldStickToRankingSnapshotOfFirstPage(t *testing.T) {
+	// GIVEN
+	rankings := [][]int{{1, 2, 3, 4, 5}, {5, 4, 3, 2, 1}}
+	var topStoriesFetchCount int
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		ranking := rankings[topStoriesFetchCount]
+		topStoriesFetchCount++
+		return ranking, nil
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	firstPage, firstErr := service.GetStoriesPage(context.Background(), TopStories, PageRequest{PageSize: 3})
+	secondPage, secondErr := service.GetStoriesPage(context.Background(), TopStories, PageRequest{PageSize: 3, PageToken: firstPage.NextPageToken})
+
+	// THEN
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("no error should be met but got: %v, %v", firstErr, secondErr)
+	} else if topStoriesFetchCount != 1 {
+		t.Errorf("ranking should be fetched once but was fetched %d times", topStoriesFetchCount)
+	}
+
+	if len(secondPage.Stories) != 2 || secondPage.Stories[0].Id != 4 || secondPage.Stories[1].Id != 5 {
+		t.Errorf("second page should follow the first page ranking but got %+v", secondPage.Stories)
+	} else if secondPage.NextPageToken != "" {
+		t.Error("last page should not have a next page token")
+	}
+}
+
+func TestGetStoriesPageShouldShareSnapshotBetweenRequestsReadingSameRanking(t *testing.T) {
+	// GIVEN
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return []int{1, 2, 3}, nil
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	// ranking fetched again by each request, with the same ids
+	service := NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches).(*hackernewsStoriesProxy)
+
+	// WHEN
+	firstPage, firstErr := service.GetStoriesPage(context.Background(), TopStories, PageRequest{PageSize: 1})
+	otherFirstPage, otherErr := service.GetStoriesPage(context.Background(), TopStories, PageRequest{PageSize: 1})
+
+	// THEN
+	if firstErr != nil || otherErr != nil {
+		t.Fatalf("no error should be met but got: %v, %v", firstErr, otherErr)
+	}
+
+	if firstPage.NextPageToken != otherFirstPage.NextPageToken {
+		t.Errorf("first pages of a same ranking should point to the same snapshot but got %s and %s", firstPage.NextPageToken, otherFirstPage.NextPageToken)
+	}
+
+	if snapshotCount := len(service.snapshots.Keys()); snapshotCount != 1 {
+		t.Errorf("expected a single snapshot kept but got %d", snapshotCount)
+	}
+}
+
+func TestGetStoriesPageShouldStartFromOffset(t *testing.T) {
+	// GIVEN
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return []int{1, 2, 3}, nil
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	page, err := service.GetStoriesPage(context.Background(), TopStories, PageRequest{Offset: 1, PageSize: 1})
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	} else if len(page.Stories) != 1 || page.Stories[0].Id != 2 {
+		t.Errorf("expected story '2' on page but got %+v", page.Stories)
+	} else if page.NextPageToken == "" {
+		t.Error("next page token should be issued when stories are left")
+	}
+}
+
+func TestGetStoriesPageShouldRejectInvalidPageTokens(t *testing.T) {
+	// GIVEN
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return []int{1, 2, 3}, nil
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	topStoriesPage, _ := service.GetStoriesPage(context.Background(), TopStories, PageRequest{PageSize: 1})
+
+	invalidTokens := map[string]struct{list StoryList; token string}{
+		"malformed": {TopStories, "not a token"},
+		"unknown snapshot": {TopStories, pageToken{snapshotId: "unknown", offset: 1}.encode()},
+		"other list": {NewStories, topStoriesPage.NextPageToken},
+	}
+
+	for name, invalidToken := range invalidTokens {
+		// WHEN
+		_, err := service.GetStoriesPage(context.Background(), invalidToken.list, PageRequest{PageSize: 1, PageToken: invalidToken.token})
+
+		// THEN
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s token should be rejected as invalid argument but got: %v", name, err)
+		}
+	}
+}
+
+func TestGetStoriesShouldBoundStoriesToAvailableOnes(t *testing.T) {
+	topStoriesIds := []int{1, 2, 3}
+
+	testCases := []struct{
+		name string
+		maxStoryCount uint32
+		expectedCount int
+	}{
+		{name: "none requested", maxStoryCount: 0, expectedCount: 0},
+		{name: "fewer than available", maxStoryCount: 2, expectedCount: 2},
+		{name: "as many as available", maxStoryCount: 3, expectedCount: 3},
+		{name: "more than available", maxStoryCount: 1000, expectedCount: 3},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// GIVEN
+			mockLiveService := MockHnLiveService{}
+			mockLiveService.MockedTopStories = func() ([]int, error) {
+				return topStoriesIds, nil
+			}
+
+			mockItemService := MockHnItemService{}
+			mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+				return &hn.Item{ID: id}, nil
+			}
+
+			var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+			var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+			var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+			// WHEN
+			page, err := service.GetStories(context.Background(), TopStories, testCase.maxStoryCount)
+
+			// THEN
+			if err != nil {
+				t.Fatalf("no error should be met but got: %v", err)
+			} else if len(page.Stories) != testCase.expectedCount {
+				t.Errorf("expected %d stories but got %d", testCase.expectedCount, len(page.Stories))
+			} else if page.AvailableCount != len(topStoriesIds) {
+				t.Errorf("expected %d available stories but got %d", len(topStoriesIds), page.AvailableCount)
+			}
+		})
+	}
+}
+
+func TestGetTopStoriesShouldFetchEachStoryOnceForConcurrentRequests(t *testing.T) {
+	// GIVEN
+	topStoriesIds := []int{1, 2, 3}
+	var fetchCounts sync.Map
+	release := make(chan struct{})
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return topStoriesIds, nil
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		fetchCount, _ := fetchCounts.LoadOrStore(id, &atomic.Int32{})
+		fetchCount.(*atomic.Int32).Add(1)
+		<-release
+		return &hn.Item{ID: id, Type: "story"}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Live: mockLiveService, Items: mockItemService}
+	var storiesCache cache.Cache[int, *Story] = cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	var service StoriesService = NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	var requests sync.WaitGroup
+	for range 10 {
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			if _, err := service.GetTopStories(context.Background(), uint32(len(topStoriesIds))); err != nil {
+				t.Errorf("no error should be met but got: %v", err)
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+	requests.Wait()
+
+	// THEN
+	for _, id := range topStoriesIds {
+		fetchCount, _ := fetchCounts.Load(id)
+		if fetchCount == nil || fetchCount.(*atomic.Int32).Load() != 1 {
+			t.Errorf("expected story '%d' to be fetched once", id)
+		}
+	}
+}
+
+func TestGetTopStoriesShouldTraceCacheLookupAndItemFetches(t *testing.T) {
+	// GIVEN
+	spans := recordSpans()
+	topStoriesIds := []int{1, 2}
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return topStoriesIds, nil
+	}
+
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id, Type: "story"}, nil
+	}
+
+	client := upstream.Client{Live: mockLiveService, Items: mockItemService}
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	service := NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	storiesCache.Add(1, &Story{Id: 1, Type: "story"})
+
+	// WHEN
+	if _, err := service.GetTopStories(context.Background(), uint32(len(topStoriesIds))); err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	}
+
+	// THEN
+	spansByName := map[string][]tracetest.SpanStub{}
+	for _, span := range spans.GetSpans() {
+		spansByName[span.Name] = append(spansByName[span.Name], span)
+	}
+
+	if len(spansByName["hn.StoryIds"]) != 1 {
+		t.Errorf("expected a span for the top stories ids fetch but got %d", len(spansByName["hn.StoryIds"]))
+	}
+
+	lookups := spansByName["stories.Lookup"]
+	if len(lookups) != 1 {
+		t.Fatalf("expected a single lookup span but got %d", len(lookups))
+	}
+
+	if hits, misses := spanAttribute(lookups[0], "hackernews.cache.hits").AsInt64(), spanAttribute(lookups[0], "hackernews.cache.misses").AsInt64(); hits != 1 || misses != 1 {
+		t.Errorf("expected 1 cache hit and 1 miss but got %d hits and %d misses", hits, misses)
+	}
+
+	itemFetches := spansByName["hn.Item"]
+	if len(itemFetches) != 1 {
+		t.Fatalf("only the missing story should be fetched, but got %d item spans", len(itemFetches))
+	}
+
+	if spanAttribute(itemFetches[0], "hackernews.item.id").AsInt64() != 2 {
+		t.Error("item span should tell which item was fetched")
+	}
+
+	if itemFetches[0].Parent.SpanID() != lookups[0].SpanContext.SpanID() {
+		t.Error("item fetch span should be a child of the lookup span")
+	}
+}
+
+func TestGetTopStoriesShouldStopFetchingStoriesOnceCancelled(t *testing.T) {
+	// GIVEN
+	topStoriesIds := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return topStoriesIds, nil
+	}
+
+	var itemCalls atomic.Int32
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		itemCalls.Add(1)
+		// client gives up while the first story is being fetched
+		cancel()
+		return &hn.Item{ID: id}, nil
+	}
+
+	client := upstream.Client{Live: mockLiveService, Items: mockItemService}
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	service := NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), 1)
+
+	// WHEN
+	_, err := service.GetTopStories(ctx, uint32(len(topStoriesIds)))
+
+	// THEN
+	if err == nil {
+		t.Error("cancelled request should fail")
+	}
+
+	if itemCalls.Load() != 1 {
+		t.Errorf("no story should be fetched once request is cancelled, but %d were", itemCalls.Load())
+	}
+}
+
+func TestGetTopStoriesShouldAbortUpstreamRequestsOnDeadline(t *testing.T) {
+	// GIVEN
+	var itemRequests atomic.Int32
+	var abortedRequests atomic.Int32
+
+	hnApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v0/topstories.json" {
+			w.Write([]byte("[1, 2, 3, 4, 5, 6, 7, 8, 9, 10]"))
+			return
+		}
+
+		itemRequests.Add(1)
+		select {
+		case <-r.Context().Done():
+			abortedRequests.Add(1)
+		case <-time.After(time.Second * 5):
+			w.Write([]byte(`{"id": 1, "type": "story"}`))
+		}
+	}))
+	defer hnApi.Close()
+
+	baseUrl, _ := url.Parse(hnApi.URL + "/v0/")
+
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	service := NewHackernewsStoriesProxy(upstream.NewClient(hnApi.Client(), baseUrl), storiesCache, uncachedRankings(), 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond * 100)
+	defer cancel()
+
+	// WHEN
+	start := time.Now()
+	_, err := service.GetTopStories(ctx, 10)
+
+	// THEN
+	if err == nil {
+		t.Fatal("request past its deadline should fail")
+	} else if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request should fail as soon as its deadline is exceeded but took %v", elapsed)
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	if itemRequests.Load() != 2 {
+		t.Errorf("only the 2 stories being fetched at deadline should have been requested, but %d were", itemRequests.Load())
+	}
+
+	if abortedRequests.Load() != itemRequests.Load() {
+		t.Errorf("every request in flight at deadline should be aborted, but only %d of %d were", abortedRequests.Load(), itemRequests.Load())
+	}
+}
+
+func TestGetItemShouldServeCachedItemsWhileCircuitIsOpen(t *testing.T) {
+	// GIVEN
+	breaker := upstream.NewCircuitBreaker(1, time.Minute, func(upstream.CircuitState) {})
+	defer breaker.Close()
+
+	var itemCalls atomic.Int32
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		itemCalls.Add(1)
+		return nil, &upstream.StatusError{Path: "item/3.json", StatusCode: http.StatusBadGateway}
+	}
+
+	client := upstream.NewCircuitBreakingClient(upstream.Client{Items: mockItemService}, breaker)
+
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	storiesCache.Add(1, &Story{Id: 1, Type: "story", Title: "cached"})
+
+	service := NewHackernewsStoriesProxy(client, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	_, failedErr := service.GetItem(context.Background(), 3)
+	cachedItem, cachedErr := service.GetItem(context.Background(), 1)
+	_, missingErr := service.GetItem(context.Background(), 2)
+
+	// THEN
+	if breaker.State() != upstream.CircuitOpen {
+		t.Fatalf("circuit should be open after the failed fetch but was %v", breaker.State())
+	}
+
+	if status.Code(failedErr) != codes.Internal {
+		t.Errorf("failure opening the circuit should be internal but got %v", failedErr)
+	}
+
+	if cachedErr != nil || cachedItem == nil || cachedItem.Title != "cached" {
+		t.Errorf("cached item should be served while circuit is open but got %v, %v", cachedItem, cachedErr)
+	}
+
+	if status.Code(missingErr) != codes.Unavailable {
+		t.Errorf("item missing from cache should be unavailable while circuit is open but got %v", missingErr)
+	}
+
+	if itemCalls.Load() != 1 {
+		t.Errorf("HackerNews API should not be requested while circuit is open, but got %d calls", itemCalls.Load())
+	}
+}
+
+func TestGetStoriesShouldServeLastStoriesFetchedAsStaleWhenHnFails(t *testing.T) {
+	// GIVEN
+	var hnFailing atomic.Bool
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		if hnFailing.Load() {
+			return nil, errors.New("top stories fetch fail")
+		}
+		return []int{1, 2}, nil
+	}
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		if hnFailing.Load() {
+			return nil, errors.New("story fetch fail")
+		}
+		return &hn.Item{ID: id, Type: "story"}, nil
+	}
+
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	service := NewHackernewsStoriesProxy(upstream.Client{Live: mockLiveService, Items: mockItemService}, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	freshPage, err := service.GetStories(context.Background(), TopStories, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// WHEN
+	hnFailing.Store(true)
+	// stories expired from cache
+	storiesCache.Clear()
+	time.Sleep(time.Millisecond * 20)
+
+	stalePage, err := service.GetStories(context.Background(), TopStories, 2)
+
+	// THEN
+	if freshPage.Stale {
+		t.Error("stories fetched from HackerNews should not be stale")
+	}
+
+	if err != nil {
+		t.Fatalf("last stories fetched should be served when HackerNews fails but got %v", err)
+	}
+
+	if len(stalePage.Stories) != 2 || stalePage.Stories[0].Id != 1 || stalePage.Stories[1].Id != 2 || stalePage.AvailableCount != 2 {
+		t.Errorf("last stories fetched should be served in their last ranking but got %v", stalePage)
+	}
+
+	if !stalePage.Stale || stalePage.Age < time.Millisecond * 20 || stalePage.Age > time.Second {
+		t.Errorf("page should be stale since the stories were fetched but got %v", stalePage.Freshness)
+	}
+}
+
+func TestGetStoriesShouldFailWhenHnFailsWithoutAnyStoryFetchedBefore(t *testing.T) {
+	// GIVEN
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		return []int{1, 2}, nil
+	}
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return nil, errors.New("story fetch fail")
+	}
+
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	service := NewHackernewsStoriesProxy(upstream.Client{Live: mockLiveService, Items: mockItemService}, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	// WHEN
+	_, err := service.GetStories(context.Background(), TopStories, 2)
+
+	// THEN
+	if status.Code(err) != codes.Internal {
+		t.Errorf("stories never fetched cannot be served but got %v", err)
+	}
+}
+
+func TestStreamTopStoriesShouldTellStalenessOfStoriesHanded(t *testing.T) {
+	// GIVEN
+	var hnFailing atomic.Bool
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		if hnFailing.Load() {
+			return nil, errors.New("top stories fetch fail")
+		}
+		return []int{1}, nil
+	}
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id, Type: "story"}, nil
+	}
+
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	service := NewHackernewsStoriesProxy(upstream.Client{Live: mockLiveService, Items: mockItemService}, storiesCache, uncachedRankings(), maxParallelFetches)
+
+	onStory := func(rank int, story *Story) error { return nil }
+
+	// WHEN
+	freshness, freshErr := service.StreamTopStories(context.Background(), 1, onStory)
+	hnFailing.Store(true)
+	staleness, staleErr := service.StreamTopStories(context.Background(), 1, onStory)
+
+	// THEN
+	if freshErr != nil || freshness.Stale {
+		t.Errorf("stories streamed from HackerNews should not be stale but got %v, %v", freshness, freshErr)
+	}
+
+	if staleErr != nil || !staleness.Stale {
+		t.Errorf("stories streamed from last top stories fetched should be stale but got %v, %v", staleness, staleErr)
+	}
+}
+
+func TestGetStoriesShouldReadRankingFromCacheWithinItsTimeToLive(t *testing.T) {
+	// GIVEN
+	var rankingFetches atomic.Int32
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		rankingFetches.Add(1)
+		return []int{1, 2}, nil
+	}
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id, Type: "story"}, nil
+	}
+
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	rankingsCache := cache.NewLruTimeToLiveCache[StoryList, *Ranking](time.Minute, 10)
+	service := NewHackernewsStoriesProxy(upstream.Client{Live: mockLiveService, Items: mockItemService}, storiesCache, rankingsCache, maxParallelFetches)
+
+	// WHEN
+	beforeFetch := time.Now()
+	firstPage, firstErr := service.GetStories(context.Background(), TopStories, 2)
+	secondPage, secondErr := service.GetStories(context.Background(), TopStories, 1)
+
+	// THEN
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("no error should be met but got %v, %v", firstErr, secondErr)
+	}
+
+	if rankingFetches.Load() != 1 {
+		t.Errorf("cached ranking should be fetched once but was fetched %d times", rankingFetches.Load())
+	}
+
+	if firstPage.RankingFetchedAt.Before(beforeFetch) || !secondPage.RankingFetchedAt.Equal(firstPage.RankingFetchedAt) {
+		t.Errorf("pages should tell when their cached ranking was fetched but got %v and %v", firstPage.Freshness, secondPage.Freshness)
+	}
+
+	if firstPage.Stale || secondPage.Stale {
+		t.Error("stories ranked from cache should not be stale")
+	}
+}
+
+func TestRefreshRankingShouldReplaceCachedRanking(t *testing.T) {
+	// GIVEN
+	var rankingFetches atomic.Int32
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		if rankingFetches.Add(1) == 1 {
+			return []int{1}, nil
+		}
+		return []int{2, 1}, nil
+	}
+	mockItemService := MockHnItemService{}
+	mockItemService.MockedItem = func(id int) (*hn.Item, error) {
+		return &hn.Item{ID: id, Type: "story"}, nil
+	}
+
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	rankingsCache := cache.NewLruTimeToLiveCache[StoryList, *Ranking](time.Minute, 10)
+	service := NewHackernewsStoriesProxy(upstream.Client{Live: mockLiveService, Items: mockItemService}, storiesCache, rankingsCache, maxParallelFetches)
+
+	if _, err := service.GetStories(context.Background(), TopStories, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// WHEN
+	refreshErr := service.RefreshRanking(context.Background(), TopStories)
+	page, err := service.GetStories(context.Background(), TopStories, 2)
+
+	// THEN
+	if refreshErr != nil || err != nil {
+		t.Fatalf("no error should be met but got %v, %v", refreshErr, err)
+	}
+
+	if len(page.Stories) != 2 || page.Stories[0].Id != 2 || page.Stories[1].Id != 1 {
+		t.Errorf("stories should be ranked by the refreshed ranking but got %v", page.Stories)
+	}
+
+	if rankingFetches.Load() != 2 {
+		t.Errorf("refreshed ranking should be read from cache, expected 2 fetches but got %d", rankingFetches.Load())
+	}
+}
+
+func TestRefreshRankingShouldKeepCachedRankingWhenHnFails(t *testing.T) {
+	// GIVEN
+	var hnFailing atomic.Bool
+
+	mockLiveService := MockHnLiveService{}
+	mockLiveService.MockedTopStories = func() ([]int, error) {
+		if hnFailing.Load() {
+			return nil, errors.New("top stories fetch fail")
+		}
+		return []int{1}, nil
+	}
+
+	rankingsCache := cache.NewLruTimeToLiveCache[StoryList, *Ranking](time.Minute, 10)
+	service := NewHackernewsStoriesProxy(upstream.Client{Live: mockLiveService}, cache.NewLruTimeToLiveCache[int, *Story](time.Minute, 10), rankingsCache, maxParallelFetches)
+
+	if err := service.RefreshRanking(context.Background(), TopStories); err != nil {
+		t.Fatal(err)
+	}
+
+	// WHEN
+	hnFailing.Store(true)
+	err := service.RefreshRanking(context.Background(), TopStories)
+
+	// THEN
+	if status.Code(err) != codes.Internal {
+		t.Errorf("failed refresh should be reported but got %v", err)
+	}
+
+	if ranking, isCached := rankingsCache.Get(TopStories); !isCached || len(ranking.Ids) != 1 {
+		t.Errorf("last ranking fetched should stay cached but got %v", ranking)
+	}
+}
+
+func TestGetItemShouldNotRetryPastRequestDeadline(t *testing.T) {
+	// GIVEN
+	var itemRequests atomic.Int32
+	hnApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		itemRequests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer hnApi.Close()
+
+	baseUrl, _ := url.Parse(hnApi.URL + "/v0/")
+	retryPolicy := upstream.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Second}
+
+	storiesCache := cache.NewTimeToLiveCache[int, *Story](time.Minute)
+	defer storiesCache.Close()
+	service := NewHackernewsStoriesProxy(upstream.NewRetryingClient(upstream.NewClient(hnApi.Client(), baseUrl), retryPolicy), storiesCache, uncachedRankings(), maxParallelFetches)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond * 300)
+	defer cancel()
+
+	// WHEN
+	start := time.Now()
+	_, err := service.GetItem(ctx, 1)
+
+	// THEN
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("expected the error answered by HackerNews API rather than the deadline being exceeded but got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Millisecond * 250 {
+		t.Errorf("request should give up retrying when the backoff exceeds its deadline but took %v", elapsed)
+	}
+
+	if itemRequests.Load() != 1 {
+		t.Errorf("expected a single request to HackerNews API but got %d", itemRequests.Load())
+	}
+}
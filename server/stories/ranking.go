@@ -0,0 +1,38 @@
+package stories
+
+import "time"
+
+// Ranked story ids of a list, as fetched from HackerNews API
+type Ranking struct {
+	Ids []int
+	FetchedAt time.Time
+}
+
+// How recent served stories are
+type Freshness struct {
+	// When the ranking of served stories was fetched from HackerNews API
+	RankingFetchedAt time.Time
+	// Set when data could not be fetched from HackerNews API, the last data fetched being served instead
+	Stale bool
+	// Time since the oldest stale data served was fetched
+	Age time.Duration
+}
+
+// Freshness of stories ranked by a ranking fetched at rankingFetchedAt, whose oldest part served from fallback store
+// was fetched at staleSince, zero when every part was fresh
+func freshness(rankingFetchedAt time.Time, staleSince time.Time) Freshness {
+	if staleSince.IsZero() {
+		return Freshness{RankingFetchedAt: rankingFetchedAt}
+	}
+
+	return Freshness{RankingFetchedAt: rankingFetchedAt, Stale: true, Age: time.Since(staleSince)}
+}
+
+// Earliest of fetch times, zero ones standing for fresh data
+func oldestFetch(fetchedAt time.Time, otherFetchedAt time.Time) time.Time {
+	if fetchedAt.IsZero() || (!otherFetchedAt.IsZero() && otherFetchedAt.Before(fetchedAt)) {
+		return otherFetchedAt
+	}
+
+	return fetchedAt
+}
@@ -0,0 +1,79 @@
+package stories
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Which part of a story list to fetch. When PageToken is set, Offset is ignored and the page
+// follows the one that issued the token, in the same ranking snapshot
+type PageRequest struct {
+	Offset uint32;
+	PageSize uint32;
+	PageToken string;
+}
+
+type StoriesPage struct {
+	Stories []Story;
+	// Number of stories in the whole list, as returned by HackerNews
+	AvailableCount int;
+	// Empty when there is no story left in the snapshot
+	NextPageToken string;
+	Freshness
+}
+
+// Ranked story ids as they were when the first page was requested
+type rankingSnapshot struct {
+	list StoryList;
+	ids []int;
+	fetchedAt time.Time;
+	// Fetch time of ids when they were served from fallback store, zero otherwise
+	staleSince time.Time;
+}
+
+// Opaque page token pointing to the snapshot that issued it, and to the offset of the next page within it
+type pageToken struct {
+	snapshotId string;
+	offset int;
+}
+
+// Random snapshot id, so that page tokens of other clients cannot be guessed
+func newSnapshotId() string {
+	return rand.Text()
+}
+
+// Identifies the ranking a snapshot is taken from by its content, so that first pages reading a same ranking share their snapshot
+func rankingKeyOf(list StoryList, ids []int) string {
+	hash := sha256.New()
+	for _, id := range ids {
+		hash.Write(binary.BigEndian.AppendUint64(nil, uint64(id)))
+	}
+
+	return fmt.Sprintf("%s-%x", list, hash.Sum(nil))
+}
+
+func (token pageToken) encode() string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%s:%d", token.snapshotId, token.offset))
+}
+
+func decodePageToken(encodedToken string) (pageToken, error) {
+	decodedToken, err := base64.RawURLEncoding.DecodeString(encodedToken)
+	if err != nil {
+		return pageToken{}, fmt.Errorf("malformed page token")
+	}
+
+	snapshotId, offset, hasSeparator := strings.Cut(string(decodedToken), ":")
+	parsedOffset, err := strconv.Atoi(offset)
+
+	if !hasSeparator || err != nil || parsedOffset < 0 {
+		return pageToken{}, fmt.Errorf("malformed page token")
+	}
+
+	return pageToken{snapshotId: snapshotId, offset: parsedOffset}, nil
+}
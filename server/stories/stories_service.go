@@ -1,5 +1,14 @@
 package stories
 
+import "context"
+
 type StoriesService interface {
-	GetTopStories(maxStoryCount uint32) (*[]Story, error)
-}
\ No newline at end of file
+	GetTopStories(ctx context.Context, maxStoryCount uint32) (*[]Story, error)
+	StreamTopStories(ctx context.Context, maxStoryCount uint32, onStory func(rank int, story *Story) error) (Freshness, error)
+	GetStories(ctx context.Context, list StoryList, maxStoryCount uint32) (*StoriesPage, error)
+	GetStoriesPage(ctx context.Context, list StoryList, pageRequest PageRequest) (*StoriesPage, error)
+	GetItem(ctx context.Context, id int) (*Story, error)
+	GetCommentTree(ctx context.Context, id int, maxDepth uint32, maxChildren uint32) (*CommentTree, error)
+	// Fetches the ranking of list again, so that requests reading it from cache get the latest one
+	RefreshRanking(ctx context.Context, list StoryList) error
+}
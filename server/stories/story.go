@@ -1,7 +1,21 @@
 package stories
 
+import "time"
+
+// HackerNews item. Mostly used for stories, but can also hold comments, jobs, polls and poll options
 type Story struct {
 	Id int;
+	Type string;
 	Title string;
 	Url string;
-}
\ No newline at end of file
+	Text string;
+	By string;
+	Time time.Time;
+	Score int;
+	Descendants int;
+	Parent int;
+	Kids []int;
+	Parts []int;
+	Dead bool;
+	Deleted bool;
+}
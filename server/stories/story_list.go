@@ -0,0 +1,43 @@
+package stories
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Lists of stories published by HackerNews, ranked the same way as on the website
+type StoryList int
+
+const (
+	TopStories StoryList = iota
+	NewStories
+	BestStories
+	AskStories
+	ShowStories
+	JobStories
+)
+
+var storyListPaths = map[StoryList]string{
+	TopStories: "topstories.json",
+	NewStories: "newstories.json",
+	BestStories: "beststories.json",
+	AskStories: "askstories.json",
+	ShowStories: "showstories.json",
+	JobStories: "jobstories.json",
+}
+
+// Path of the list relative to HackerNews API base url
+func (list StoryList) path() (string, bool) {
+	path, exists := storyListPaths[list]
+	return path, exists
+}
+
+// Name of the list in HackerNews API, as displayed in cache keys
+func (list StoryList) String() string {
+	path, exists := list.path()
+	if !exists {
+		return strconv.Itoa(int(list))
+	}
+
+	return strings.TrimSuffix(path, ".json")
+}
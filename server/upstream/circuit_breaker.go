@@ -0,0 +1,165 @@
+package upstream
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Returned instead of sending requests to HackerNews API while its circuit breaker is open
+var ErrCircuitOpen = errors.New("HackerNews API circuit breaker is open")
+
+type CircuitState int
+
+const (
+	// Requests are sent, consecutive failures being counted
+	CircuitClosed CircuitState = iota
+	// Requests fail fast, without being sent
+	CircuitOpen
+	// A single trial request is sent, whose outcome closes or opens the circuit again
+	CircuitHalfOpen
+)
+
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Stops sending requests to HackerNews API while it is failing, so that they fail fast rather than waiting for its timeout.
+// The circuit opens after threshold consecutive failures, and turns half-open once openDuration elapsed
+type CircuitBreaker struct {
+	threshold uint32
+	openDuration time.Duration
+	onChange func(state CircuitState)
+
+	mutex sync.Mutex
+	state CircuitState
+	consecutiveFailures uint32
+	trialInFlight bool
+	halfOpenTimer *time.Timer
+	rejectedCount uint64
+	closed bool
+}
+
+// onChange is called with the new state each time it changes. Calls are serialized, and must not call the breaker back
+func NewCircuitBreaker(threshold uint32, openDuration time.Duration, onChange func(state CircuitState)) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: max(1, threshold),
+		openDuration: openDuration,
+		onChange: onChange,
+		state: CircuitClosed,
+	}
+}
+
+// Outcome of a request let through by the breaker
+type outcome int
+
+const (
+	succeeded outcome = iota
+	failed
+	// given up by its caller, which tells nothing about HackerNews API
+	abandoned
+)
+
+// Lets a request through unless the circuit is open, or half-open with its trial request already sent.
+// Requests let through must report their outcome with record
+func (b *CircuitBreaker) allow() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch {
+	case b.state == CircuitOpen, b.state == CircuitHalfOpen && b.trialInFlight:
+		b.rejectedCount++
+		return ErrCircuitOpen
+	case b.state == CircuitHalfOpen:
+		b.trialInFlight = true
+	}
+
+	return nil
+}
+
+func (b *CircuitBreaker) record(result outcome) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case CircuitClosed:
+		if result == succeeded {
+			b.consecutiveFailures = 0
+		} else if result == failed {
+			b.consecutiveFailures++
+			if b.consecutiveFailures >= b.threshold {
+				b.openLocked()
+			}
+		}
+	case CircuitHalfOpen:
+		b.trialInFlight = false
+		if result == succeeded {
+			b.consecutiveFailures = 0
+			b.changeLocked(CircuitClosed)
+		} else if result == failed {
+			b.openLocked()
+		}
+	case CircuitOpen:
+		// outcome of a request sent before the circuit opened
+	}
+}
+
+func (b *CircuitBreaker) State() CircuitState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state
+}
+
+// Number of requests failed fast since the breaker was created
+func (b *CircuitBreaker) RejectedCount() uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.rejectedCount
+}
+
+// Stops turning the circuit half-open once open, so an open circuit stays open
+func (b *CircuitBreaker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.closed = true
+	if b.halfOpenTimer != nil {
+		b.halfOpenTimer.Stop()
+	}
+}
+
+func (b *CircuitBreaker) openLocked() {
+	b.changeLocked(CircuitOpen)
+
+	if b.closed {
+		return
+	}
+
+	// turned half-open on a timer rather than by the next request, so that health checks report the server as serving
+	// again and traffic comes back to send the trial request
+	b.halfOpenTimer = time.AfterFunc(b.openDuration, func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		if b.state == CircuitOpen && !b.closed {
+			b.trialInFlight = false
+			b.changeLocked(CircuitHalfOpen)
+		}
+	})
+}
+
+func (b *CircuitBreaker) changeLocked(state CircuitState) {
+	b.state = state
+	b.onChange(state)
+}
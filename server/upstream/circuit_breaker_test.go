@@ -0,0 +1,147 @@
+package upstream
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// Records states a breaker changes to
+type stateChanges struct {
+	mutex sync.Mutex
+	states []CircuitState
+}
+
+func (c *stateChanges) record(state CircuitState) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.states = append(c.states, state)
+}
+
+func (c *stateChanges) get() []CircuitState {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]CircuitState(nil), c.states...)
+}
+
+func TestCircuitBreakerShouldOpenOnlyAfterThresholdConsecutiveFailures(t *testing.T) {
+	// GIVEN
+	changes := &stateChanges{}
+	breaker := NewCircuitBreaker(3, time.Minute, changes.record)
+	defer breaker.Close()
+
+	// WHEN
+	for _, result := range []outcome{failed, failed, succeeded, failed, abandoned, failed} {
+		breaker.allow()
+		breaker.record(result)
+	}
+
+	// THEN
+	if breaker.State() != CircuitClosed {
+		t.Fatal("failures interrupted by a success should not open the circuit")
+	}
+
+	// WHEN
+	breaker.allow()
+	breaker.record(failed)
+
+	// THEN
+	if breaker.State() != CircuitOpen {
+		t.Error("circuit should be open after 3 consecutive failures")
+	}
+
+	if err := breaker.allow(); err != ErrCircuitOpen {
+		t.Errorf("open circuit should reject requests but got %v", err)
+	}
+
+	if breaker.RejectedCount() != 1 {
+		t.Errorf("expected 1 rejected request but got %d", breaker.RejectedCount())
+	}
+
+	if states := changes.get(); len(states) != 1 || states[0] != CircuitOpen {
+		t.Errorf("a single change to open should be notified but got %v", states)
+	}
+}
+
+func TestCircuitBreakerShouldLetASingleTrialThroughOnceHalfOpen(t *testing.T) {
+	// GIVEN
+	changes := &stateChanges{}
+	breaker := NewCircuitBreaker(1, time.Millisecond * 20, changes.record)
+	defer breaker.Close()
+
+	breaker.allow()
+	breaker.record(failed)
+
+	// WHEN
+	time.Sleep(time.Millisecond * 100)
+
+	// THEN
+	if breaker.State() != CircuitHalfOpen {
+		t.Fatalf("circuit should be half-open once its open duration elapsed but was %v", breaker.State())
+	}
+
+	if err := breaker.allow(); err != nil {
+		t.Errorf("trial request should be let through but got %v", err)
+	}
+
+	if err := breaker.allow(); err != ErrCircuitOpen {
+		t.Errorf("requests should be rejected while trial is in flight but got %v", err)
+	}
+
+	// WHEN
+	breaker.record(succeeded)
+
+	// THEN
+	if breaker.State() != CircuitClosed {
+		t.Errorf("successful trial should close the circuit but was %v", breaker.State())
+	}
+
+	expectedStates := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if states := changes.get(); len(states) != len(expectedStates) || states[0] != CircuitOpen || states[1] != CircuitHalfOpen || states[2] != CircuitClosed {
+		t.Errorf("expected changes %v but got %v", expectedStates, states)
+	}
+}
+
+func TestCircuitBreakerShouldOpenAgainOnFailedTrial(t *testing.T) {
+	// GIVEN
+	breaker := NewCircuitBreaker(1, time.Millisecond * 20, func(CircuitState) {})
+	defer breaker.Close()
+
+	breaker.allow()
+	breaker.record(failed)
+	time.Sleep(time.Millisecond * 100)
+
+	// WHEN
+	breaker.allow()
+	breaker.record(failed)
+
+	// THEN
+	if breaker.State() != CircuitOpen {
+		t.Errorf("failed trial should open the circuit again but was %v", breaker.State())
+	}
+}
+
+func TestCircuitBreakerShouldLetAnotherTrialThroughWhenTrialIsAbandoned(t *testing.T) {
+	// GIVEN
+	breaker := NewCircuitBreaker(1, time.Millisecond * 20, func(CircuitState) {})
+	defer breaker.Close()
+
+	breaker.allow()
+	breaker.record(failed)
+	time.Sleep(time.Millisecond * 100)
+
+	// WHEN
+	breaker.allow()
+	breaker.record(abandoned)
+
+	// THEN
+	if breaker.State() != CircuitHalfOpen {
+		t.Errorf("abandoned trial should leave the circuit half-open but was %v", breaker.State())
+	}
+
+	if err := breaker.allow(); err != nil {
+		t.Errorf("another trial should be let through but got %v", err)
+	}
+}
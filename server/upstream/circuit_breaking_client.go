@@ -0,0 +1,91 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+
+	hn "github.com/peterhellberg/hn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Decorates client services so that requests go through breaker, failing fast with ErrCircuitOpen while it is open.
+// Transient failures, as classified by IsRetryable, count against HackerNews API, while requests given up by their caller do not count at all
+func NewCircuitBreakingClient(client Client, breaker *CircuitBreaker) Client {
+	return Client{
+		Items: circuitBreakingItemsService{next: client.Items, breaker: breaker},
+		Users: circuitBreakingUsersService{next: client.Users, breaker: breaker},
+		Live: circuitBreakingLiveService{next: client.Live, breaker: breaker},
+	}
+}
+
+// gRPC status of a failure to get data from HackerNews API, described by format and args: unavailable when caused by an open circuit,
+// either directly or through a status built by this function, internal otherwise
+func ErrorStatus(err error, format string, args ...any) error {
+	code := codes.Internal
+	if errors.Is(err, ErrCircuitOpen) || status.Code(err) == codes.Unavailable {
+		code = codes.Unavailable
+	}
+
+	return status.Errorf(code, format, args...)
+}
+
+func guard[T any](ctx context.Context, breaker *CircuitBreaker, request func() (T, error)) (T, error) {
+	if err := breaker.allow(); err != nil {
+		var none T
+		return none, err
+	}
+
+	value, err := request()
+
+	switch {
+	case ctx.Err() != nil:
+		breaker.record(abandoned)
+	case err != nil && IsRetryable(err):
+		breaker.record(failed)
+	default:
+		// HackerNews API answered, even if with a client error
+		breaker.record(succeeded)
+	}
+
+	return value, err
+}
+
+type circuitBreakingItemsService struct {
+	next ItemsService
+	breaker *CircuitBreaker
+}
+
+func (s circuitBreakingItemsService) Get(ctx context.Context, id int) (*hn.Item, error) {
+	return guard(ctx, s.breaker, func() (*hn.Item, error) {
+		return s.next.Get(ctx, id)
+	})
+}
+
+type circuitBreakingUsersService struct {
+	next UsersService
+	breaker *CircuitBreaker
+}
+
+func (s circuitBreakingUsersService) Get(ctx context.Context, id string) (*hn.User, error) {
+	return guard(ctx, s.breaker, func() (*hn.User, error) {
+		return s.next.Get(ctx, id)
+	})
+}
+
+type circuitBreakingLiveService struct {
+	next LiveService
+	breaker *CircuitBreaker
+}
+
+func (s circuitBreakingLiveService) TopStories(ctx context.Context) ([]int, error) {
+	return guard(ctx, s.breaker, func() ([]int, error) {
+		return s.next.TopStories(ctx)
+	})
+}
+
+func (s circuitBreakingLiveService) StoryIds(ctx context.Context, path string) ([]int, error) {
+	return guard(ctx, s.breaker, func() ([]int, error) {
+		return s.next.StoryIds(ctx, path)
+	})
+}
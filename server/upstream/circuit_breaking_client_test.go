@@ -0,0 +1,100 @@
+package upstream
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	hn "github.com/peterhellberg/hn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreakingClientShouldFailFastWhileOpen(t *testing.T) {
+	// GIVEN
+	breaker := NewCircuitBreaker(2, time.Minute, func(CircuitState) {})
+	defer breaker.Close()
+
+	var calls atomic.Int32
+	live := MockLiveService{MockedTopStories: func() ([]int, error) {
+		calls.Add(1)
+		time.Sleep(time.Millisecond * 50)
+		return nil, errTimeout
+	}}
+
+	client := NewCircuitBreakingClient(Client{Live: live}, breaker)
+	client.Live.TopStories(context.Background())
+	client.Live.TopStories(context.Background())
+
+	// WHEN
+	start := time.Now()
+	_, err := client.Live.TopStories(context.Background())
+
+	// THEN
+	if err != ErrCircuitOpen {
+		t.Errorf("request should fail with open circuit error but got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Millisecond * 10 {
+		t.Errorf("request should fail fast but took %v", elapsed)
+	}
+
+	if calls.Load() != 2 {
+		t.Errorf("HackerNews API should not be requested while circuit is open, expected 2 calls but got %d", calls.Load())
+	}
+}
+
+func TestCircuitBreakingClientShouldOnlyCountTransientFailures(t *testing.T) {
+	// GIVEN
+	breaker := NewCircuitBreaker(1, time.Minute, func(CircuitState) {})
+	defer breaker.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errNotFound := &StatusError{Path: "item/1.json", StatusCode: http.StatusNotFound}
+	items := MockItemsService{MockedGet: func(id int) (*hn.Item, error) {
+		if id == 1 {
+			return nil, errNotFound
+		}
+
+		// caller gives up while the request fails
+		cancel()
+		return nil, errTimeout
+	}}
+
+	client := NewCircuitBreakingClient(Client{Items: items}, breaker)
+
+	// WHEN
+	_, notFoundErr := client.Items.Get(context.Background(), 1)
+	_, abandonedErr := client.Items.Get(ctx, 2)
+
+	// THEN
+	if notFoundErr != errNotFound || abandonedErr != errTimeout {
+		t.Errorf("errors of requests should be returned as is but got %v and %v", notFoundErr, abandonedErr)
+	}
+
+	if breaker.State() != CircuitClosed {
+		t.Error("client errors and abandoned requests should not open the circuit")
+	}
+}
+
+func TestErrorStatusShouldReportOpenCircuitAsUnavailable(t *testing.T) {
+	// GIVEN
+	openCircuitStatus := ErrorStatus(ErrCircuitOpen, "could not fetch story. Cause: %v", ErrCircuitOpen)
+
+	// WHEN
+	wrappedStatus := ErrorStatus(openCircuitStatus, "could not fetch stories. Cause: %v", openCircuitStatus)
+	otherStatus := ErrorStatus(errUnavailable, "could not fetch story. Cause: %v", errUnavailable)
+
+	// THEN
+	if status.Code(openCircuitStatus) != codes.Unavailable || status.Code(wrappedStatus) != codes.Unavailable {
+		t.Errorf("open circuit should be reported as unavailable but got %v and %v", openCircuitStatus, wrappedStatus)
+	}
+
+	if status.Code(otherStatus) != codes.Internal {
+		t.Errorf("other failures should be reported as internal but got %v", otherStatus)
+	}
+}
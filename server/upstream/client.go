@@ -0,0 +1,133 @@
+package upstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	hn "github.com/peterhellberg/hn"
+)
+
+// Context-aware counterpart of HackerNews client: each request is bound to the context of its call,
+// and aborted as soon as that context is cancelled or its deadline exceeded
+type Client struct {
+	Items ItemsService
+	Users UsersService
+	Live LiveService
+}
+
+type ItemsService interface {
+	Get(ctx context.Context, id int) (*hn.Item, error)
+}
+
+type UsersService interface {
+	Get(ctx context.Context, id string) (*hn.User, error)
+}
+
+// Ranked ids of story lists
+type LiveService interface {
+	TopStories(ctx context.Context) ([]int, error)
+	// Ids of the list at path relative to HackerNews API base url, e.g. newstories.json
+	StoryIds(ctx context.Context, path string) ([]int, error)
+}
+
+// Sends requests through httpClient, resolving their paths against baseUrl, which must end with a slash
+func NewClient(httpClient *http.Client, baseUrl *url.URL) Client {
+	hnApi := api{httpClient: httpClient, baseUrl: baseUrl}
+
+	return Client{
+		Items: itemsService{api: hnApi},
+		Users: usersService{api: hnApi},
+		Live: liveService{api: hnApi},
+	}
+}
+
+// Error status answered by HackerNews API, whose body is not decoded
+type StatusError struct {
+	Path string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HackerNews API answered %d %s to %s", e.StatusCode, http.StatusText(e.StatusCode), e.Path)
+}
+
+type itemsService struct {
+	api api
+}
+
+func (s itemsService) Get(ctx context.Context, id int) (*hn.Item, error) {
+	var item hn.Item
+	if err := s.api.get(ctx, fmt.Sprintf("item/%d.json", id), &item); err != nil {
+		return nil, err
+	}
+
+	// same default as HackerNews client, for stories without link
+	if item.Type == "story" && item.URL == "" {
+		item.URL = fmt.Sprintf("https://news.ycombinator.com/item?id=%d", id)
+	}
+
+	return &item, nil
+}
+
+type usersService struct {
+	api api
+}
+
+func (s usersService) Get(ctx context.Context, id string) (*hn.User, error) {
+	var user hn.User
+	// nicknames are escaped so that they cannot point to another path of HackerNews API
+	if err := s.api.get(ctx, fmt.Sprintf("user/%s.json", url.PathEscape(id)), &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+type liveService struct {
+	api api
+}
+
+func (s liveService) TopStories(ctx context.Context) ([]int, error) {
+	return s.StoryIds(ctx, "topstories.json")
+}
+
+func (s liveService) StoryIds(ctx context.Context, path string) ([]int, error) {
+	var ids []int
+	if err := s.api.get(ctx, path, &ids); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+type api struct {
+	httpClient *http.Client
+	baseUrl *url.URL
+}
+
+// Decodes the JSON answered by HackerNews API at path into value. Answers other than 2xx are returned as StatusError
+func (a api) get(ctx context.Context, path string, value any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseUrl.JoinPath(path).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := a.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return &StatusError{Path: path, StatusCode: response.StatusCode}
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(value); err != nil {
+		return fmt.Errorf("could not decode answer of HackerNews API to %s: %w", path, err)
+	}
+
+	return nil
+}
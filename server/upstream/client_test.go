@@ -0,0 +1,135 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) Client {
+	api := httptest.NewServer(handler)
+	t.Cleanup(api.Close)
+
+	baseUrl, _ := url.Parse(api.URL + "/v0/")
+
+	return NewClient(api.Client(), baseUrl)
+}
+
+func TestClientShouldRequestApiPaths(t *testing.T) {
+	// GIVEN
+	var requestedPaths []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+
+		switch r.URL.Path {
+		case "/v0/item/8863.json":
+			w.Write([]byte(`{"id": 8863, "type": "story"}`))
+		case "/v0/user/pg.json":
+			w.Write([]byte(`{"id": "pg", "karma": 155111}`))
+		default:
+			w.Write([]byte("[3, 2, 1]"))
+		}
+	})
+	ctx := context.Background()
+
+	// WHEN
+	item, itemErr := client.Items.Get(ctx, 8863)
+	user, userErr := client.Users.Get(ctx, "pg")
+	topIds, topErr := client.Live.TopStories(ctx)
+	newIds, newErr := client.Live.StoryIds(ctx, "newstories.json")
+
+	// THEN
+	if itemErr != nil || userErr != nil || topErr != nil || newErr != nil {
+		t.Fatalf("requests should succeed, got %v, %v, %v, %v", itemErr, userErr, topErr, newErr)
+	}
+
+	expectedPaths := []string{"/v0/item/8863.json", "/v0/user/pg.json", "/v0/topstories.json", "/v0/newstories.json"}
+	if len(requestedPaths) != len(expectedPaths) {
+		t.Fatalf("expected paths %v but got %v", expectedPaths, requestedPaths)
+	}
+	for i, path := range expectedPaths {
+		if requestedPaths[i] != path {
+			t.Errorf("expected path %s but got %s", path, requestedPaths[i])
+		}
+	}
+
+	if item.URL != "https://news.ycombinator.com/item?id=8863" {
+		t.Errorf("story without link should default to its HackerNews page, got '%s'", item.URL)
+	}
+
+	if user.Karma != 155111 {
+		t.Errorf("expected karma 155111 but got %d", user.Karma)
+	}
+
+	if len(topIds) != 3 || len(newIds) != 3 {
+		t.Errorf("expected 3 ids per list but got %v and %v", topIds, newIds)
+	}
+}
+
+func TestClientShouldEscapeNicknamesInUserPath(t *testing.T) {
+	// GIVEN
+	var requestedPath string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.EscapedPath()
+		w.Write([]byte("null"))
+	})
+
+	// WHEN
+	_, err := client.Users.Get(context.Background(), "../item/8863")
+
+	// THEN
+	if err != nil {
+		t.Fatalf("request should succeed but got %v", err)
+	}
+
+	if requestedPath != "/v0/user/..%2Fitem%2F8863.json" {
+		t.Errorf("nickname should stay within the user path but %s was requested", requestedPath)
+	}
+}
+
+func TestClientShouldAbortRequestOnceContextIsCancelled(t *testing.T) {
+	// GIVEN
+	requestAborted := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(requestAborted)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond * 50, cancel)
+
+	// WHEN
+	_, err := client.Items.Get(ctx, 8863)
+
+	// THEN
+	if err == nil {
+		t.Error("cancelled request should fail")
+	}
+
+	select {
+	case <-requestAborted:
+	case <-time.After(time.Second):
+		t.Error("upstream request should be aborted")
+	}
+}
+
+func TestClientShouldReturnErrorStatusesAsStatusErrors(t *testing.T) {
+	// GIVEN
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<html>Service Unavailable</html>"))
+	})
+
+	// WHEN
+	_, err := client.Users.Get(context.Background(), "pg")
+
+	// THEN
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected a 503 status error but got %v", err)
+	}
+}
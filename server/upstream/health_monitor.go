@@ -0,0 +1,104 @@
+package upstream
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracks whether HackerNews API is healthy from the outcome of the requests sent to it.
+// It turns unhealthy after threshold consecutive failures, and healthy again at the first success.
+// While unhealthy, HackerNews API is probed every probeInterval, so that its recovery is noticed even once traffic stopped
+type HealthMonitor struct {
+	threshold uint32
+	probe func() error
+	probeInterval time.Duration
+	onChange func(healthy bool)
+
+	mutex sync.Mutex
+	consecutiveFailures uint32
+	healthy bool
+	stopProbing chan struct{}
+	closed bool
+}
+
+// onChange is called with the new health each time it changes. Calls are serialized, and must not call the monitor back
+func NewHealthMonitor(threshold uint32, probe func() error, probeInterval time.Duration, onChange func(healthy bool)) *HealthMonitor {
+	return &HealthMonitor{
+		threshold: max(1, threshold),
+		probe: probe,
+		probeInterval: probeInterval,
+		onChange: onChange,
+		healthy: true,
+	}
+}
+
+// Records the outcome of a request to HackerNews API, nil meaning success
+func (m *HealthMonitor) Record(err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err == nil {
+		m.consecutiveFailures = 0
+
+		if !m.healthy {
+			m.healthy = true
+			m.stopProbingLocked()
+			m.onChange(true)
+		}
+		return
+	}
+
+	m.consecutiveFailures++
+
+	if m.healthy && m.consecutiveFailures >= m.threshold {
+		m.healthy = false
+		m.startProbingLocked()
+		m.onChange(false)
+	}
+}
+
+func (m *HealthMonitor) Healthy() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.healthy
+}
+
+// Stops probing. Health is still tracked from recorded requests
+func (m *HealthMonitor) Close() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.closed = true
+	m.stopProbingLocked()
+}
+
+func (m *HealthMonitor) startProbingLocked() {
+	if m.closed || m.stopProbing != nil || m.probe == nil {
+		return
+	}
+
+	m.stopProbing = make(chan struct{})
+	go m.probeUntilStopped(m.stopProbing)
+}
+
+func (m *HealthMonitor) stopProbingLocked() {
+	if m.stopProbing != nil {
+		close(m.stopProbing)
+		m.stopProbing = nil
+	}
+}
+
+func (m *HealthMonitor) probeUntilStopped(stop chan struct{}) {
+	ticker := time.NewTicker(m.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.Record(m.probe())
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package upstream
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errUpstream = errors.New("upstream failure")
+
+func TestHealthMonitorShouldTurnUnhealthyOnlyAfterThresholdConsecutiveFailures(t *testing.T) {
+	// GIVEN
+	var changes []bool
+	monitor := NewHealthMonitor(3, nil, time.Minute, func(healthy bool) {
+		changes = append(changes, healthy)
+	})
+	defer monitor.Close()
+
+	// WHEN
+	monitor.Record(errUpstream)
+	monitor.Record(errUpstream)
+	monitor.Record(nil)
+	monitor.Record(errUpstream)
+	monitor.Record(errUpstream)
+
+	// THEN
+	if !monitor.Healthy() || len(changes) != 0 {
+		t.Fatal("failures interrupted by a success should not turn monitor unhealthy")
+	}
+
+	// WHEN
+	monitor.Record(errUpstream)
+	monitor.Record(errUpstream)
+
+	// THEN
+	if monitor.Healthy() {
+		t.Error("monitor should be unhealthy after 3 consecutive failures")
+	}
+
+	if len(changes) != 1 || changes[0] {
+		t.Errorf("a single change to unhealthy should be notified but got %v", changes)
+	}
+}
+
+func TestHealthMonitorShouldTurnHealthyAgainOnFirstSuccess(t *testing.T) {
+	// GIVEN
+	var changes []bool
+	monitor := NewHealthMonitor(1, nil, time.Minute, func(healthy bool) {
+		changes = append(changes, healthy)
+	})
+	defer monitor.Close()
+
+	monitor.Record(errUpstream)
+
+	// WHEN
+	monitor.Record(nil)
+	monitor.Record(nil)
+
+	// THEN
+	if !monitor.Healthy() {
+		t.Error("monitor should be healthy again after a success")
+	}
+
+	if len(changes) != 2 || changes[0] || !changes[1] {
+		t.Errorf("expected changes [false true] but got %v", changes)
+	}
+}
+
+func TestHealthMonitorShouldProbeUpstreamWhileUnhealthy(t *testing.T) {
+	// GIVEN
+	var probeCalls atomic.Int32
+	var upstreamRecovered atomic.Bool
+
+	probe := func() error {
+		probeCalls.Add(1)
+		if upstreamRecovered.Load() {
+			return nil
+		}
+		return errUpstream
+	}
+
+	recovered := make(chan struct{})
+	monitor := NewHealthMonitor(1, probe, time.Millisecond * 10, func(healthy bool) {
+		if healthy {
+			close(recovered)
+		}
+	})
+	defer monitor.Close()
+
+	if probeCalls.Load() != 0 {
+		t.Fatal("healthy upstream should not be probed")
+	}
+
+	// WHEN
+	monitor.Record(errUpstream)
+	time.Sleep(time.Millisecond * 50)
+	upstreamRecovered.Store(true)
+
+	// THEN
+	select {
+	case <-recovered:
+	case <-time.After(time.Second):
+		t.Fatal("monitor should turn healthy once a probe succeeds")
+	}
+
+	callsAfterRecovery := probeCalls.Load()
+	time.Sleep(time.Millisecond * 50)
+
+	if probeCalls.Load() != callsAfterRecovery {
+		t.Error("probing should stop once upstream is healthy again")
+	}
+}
@@ -0,0 +1,33 @@
+package upstream
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Reports the outcome of every request sent to HackerNews API to its health monitor.
+// Server errors count as failures just like transport errors, while requests given up by their caller are not reported
+type monitoredTransport struct {
+	next http.RoundTripper
+	monitor *HealthMonitor
+}
+
+func NewMonitoredTransport(next http.RoundTripper, monitor *HealthMonitor) http.RoundTripper {
+	return &monitoredTransport{next: next, monitor: monitor}
+}
+
+func (t *monitoredTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := t.next.RoundTrip(request)
+
+	if request.Context().Err() != nil {
+		return response, err
+	}
+
+	if err == nil && response.StatusCode >= http.StatusInternalServerError {
+		t.monitor.Record(fmt.Errorf("HackerNews API answered %s", response.Status))
+	} else {
+		t.monitor.Record(err)
+	}
+
+	return response, err
+}
@@ -0,0 +1,77 @@
+package upstream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMonitoredTransportShouldCountServerErrorsAsFailures(t *testing.T) {
+	// GIVEN
+	statusCode := http.StatusServiceUnavailable
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer upstream.Close()
+
+	monitor := NewHealthMonitor(2, nil, time.Minute, func(bool) {})
+	client := &http.Client{Transport: NewMonitoredTransport(http.DefaultTransport, monitor)}
+
+	get := func() {
+		response, err := client.Get(upstream.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+	}
+
+	// WHEN
+	get()
+	get()
+
+	// THEN
+	if monitor.Healthy() {
+		t.Fatal("server errors should turn monitor unhealthy")
+	}
+
+	// WHEN
+	statusCode = http.StatusNotFound
+	get()
+
+	// THEN
+	if !monitor.Healthy() {
+		t.Error("client errors should not count as upstream failures")
+	}
+}
+
+func TestMonitoredTransportShouldNotCountRequestsGivenUpByCaller(t *testing.T) {
+	// GIVEN
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	monitor := NewHealthMonitor(1, nil, time.Minute, func(bool) {})
+	client := &http.Client{Transport: NewMonitoredTransport(http.DefaultTransport, monitor)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond * 20)
+	defer cancel()
+
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL, nil)
+
+	// WHEN
+	_, err := client.Do(request)
+
+	// THEN
+	if err == nil {
+		t.Fatal("request should have been given up")
+	}
+
+	if !monitor.Healthy() {
+		t.Error("requests given up by their caller should not count as upstream failures")
+	}
+}
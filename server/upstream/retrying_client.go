@@ -0,0 +1,134 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"time"
+
+	hn "github.com/peterhellberg/hn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// How requests to HackerNews API failing transiently are retried
+type RetryPolicy struct {
+	// Total number of attempts of a request, 1 disabling retries
+	MaxAttempts uint32
+	// Wait before the first retry, doubled on each following one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Wait before the next attempt, once given number of attempts failed. Between half and the whole of the exponential backoff,
+// so that requests failing together do not retry together
+func (policy RetryPolicy) backoff(failedAttempts uint32) time.Duration {
+	backoff := policy.InitialBackoff
+	for range failedAttempts - 1 {
+		if backoff >= policy.MaxBackoff {
+			break
+		}
+		backoff *= 2
+	}
+	backoff = min(backoff, policy.MaxBackoff)
+
+	return backoff / 2 + rand.N(backoff / 2 + 1)
+}
+
+// Decorates client services so that requests failing transiently are retried according to policy.
+// Retries stop as soon as the context of the request is done, or once its deadline would be exceeded before the next attempt
+func NewRetryingClient(client Client, policy RetryPolicy) Client {
+	return Client{
+		Items: retryingItemsService{next: client.Items, policy: policy},
+		Users: retryingUsersService{next: client.Users, policy: policy},
+		Live: retryingLiveService{next: client.Live, policy: policy},
+	}
+}
+
+// Whether a request failing with err may succeed if sent again: on server errors, rate limiting,
+// and transport failures such as timeouts or reset connections. Cancelled requests are not retried
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var transportErr *url.Error
+	return errors.As(err, &transportErr)
+}
+
+// Calls request until it succeeds, fails with an error which is not retryable, or attempts are exhausted.
+// The error of the last attempt is returned
+func retry[T any](ctx context.Context, policy RetryPolicy, request func() (T, error)) (T, error) {
+	for attempt := uint32(1); ; attempt++ {
+		value, err := request()
+		if err == nil || attempt >= policy.MaxAttempts || !IsRetryable(err) || ctx.Err() != nil {
+			return value, err
+		}
+
+		backoff := policy.backoff(attempt)
+		if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Until(deadline) < backoff {
+			return value, err
+		}
+
+		slog.Debug("retrying request to HackerNews API", "attempt", attempt + 1, "backoff", backoff, "cause", err)
+		trace.SpanFromContext(ctx).AddEvent("hackernews.retry", trace.WithAttributes(
+			attribute.Int("hackernews.retry.attempt", int(attempt + 1)),
+			attribute.String("hackernews.retry.cause", err.Error()),
+		))
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return value, err
+		}
+	}
+}
+
+type retryingItemsService struct {
+	next ItemsService
+	policy RetryPolicy
+}
+
+func (s retryingItemsService) Get(ctx context.Context, id int) (*hn.Item, error) {
+	return retry(ctx, s.policy, func() (*hn.Item, error) {
+		return s.next.Get(ctx, id)
+	})
+}
+
+type retryingUsersService struct {
+	next UsersService
+	policy RetryPolicy
+}
+
+func (s retryingUsersService) Get(ctx context.Context, id string) (*hn.User, error) {
+	return retry(ctx, s.policy, func() (*hn.User, error) {
+		return s.next.Get(ctx, id)
+	})
+}
+
+type retryingLiveService struct {
+	next LiveService
+	policy RetryPolicy
+}
+
+func (s retryingLiveService) TopStories(ctx context.Context) ([]int, error) {
+	return retry(ctx, s.policy, func() ([]int, error) {
+		return s.next.TopStories(ctx)
+	})
+}
+
+func (s retryingLiveService) StoryIds(ctx context.Context, path string) ([]int, error) {
+	return retry(ctx, s.policy, func() ([]int, error) {
+		return s.next.StoryIds(ctx, path)
+	})
+}
@@ -0,0 +1,227 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	hn "github.com/peterhellberg/hn"
+)
+
+type MockItemsService struct {
+	MockedGet func(id int) (*hn.Item, error)
+}
+
+func (m MockItemsService) Get(_ context.Context, id int) (*hn.Item, error) {
+	return m.MockedGet(id)
+}
+
+type MockLiveService struct {
+	MockedTopStories func() ([]int, error)
+	MockedStoryIds func(path string) ([]int, error)
+}
+
+func (m MockLiveService) TopStories(_ context.Context) ([]int, error) {
+	return m.MockedTopStories()
+}
+
+func (m MockLiveService) StoryIds(_ context.Context, path string) ([]int, error) {
+	return m.MockedStoryIds(path)
+}
+
+var errTimeout = &url.Error{Op: "Get", URL: "https://hacker-news.firebaseio.com/v0/topstories.json", Err: errors.New("i/o timeout")}
+var errUnavailable = &StatusError{Path: "item/1.json", StatusCode: http.StatusServiceUnavailable}
+
+var fastRetries = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond * 4}
+
+func TestRetryingClientShouldRetryIntermittentFailures(t *testing.T) {
+	// GIVEN
+	var itemCalls atomic.Int32
+	items := MockItemsService{MockedGet: func(id int) (*hn.Item, error) {
+		// every other call fails
+		if itemCalls.Add(1) % 2 == 1 {
+			return nil, errUnavailable
+		}
+		return &hn.Item{ID: id}, nil
+	}}
+
+	var liveCalls atomic.Int32
+	live := MockLiveService{MockedTopStories: func() ([]int, error) {
+		if liveCalls.Add(1) < 3 {
+			return nil, errTimeout
+		}
+		return []int{1, 2, 3}, nil
+	}}
+
+	client := NewRetryingClient(Client{Items: items, Live: live}, fastRetries)
+
+	// WHEN
+	ids, idsErr := client.Live.TopStories(context.Background())
+	var itemErrs []error
+	for _, id := range ids {
+		if _, err := client.Items.Get(context.Background(), id); err != nil {
+			itemErrs = append(itemErrs, err)
+		}
+	}
+
+	// THEN
+	if idsErr != nil || len(ids) != 3 {
+		t.Fatalf("top stories should be fetched on third attempt but got %v, %v", ids, idsErr)
+	}
+
+	if liveCalls.Load() != 3 {
+		t.Errorf("expected 3 top stories attempts but got %d", liveCalls.Load())
+	}
+
+	if len(itemErrs) != 0 {
+		t.Errorf("every item should be fetched on retry but got errors %v", itemErrs)
+	}
+
+	if itemCalls.Load() != 6 {
+		t.Errorf("each item should be fetched on its second attempt, expected 6 calls but got %d", itemCalls.Load())
+	}
+}
+
+func TestRetryingClientShouldGiveUpAfterMaxAttempts(t *testing.T) {
+	// GIVEN
+	var calls atomic.Int32
+	live := MockLiveService{MockedStoryIds: func(path string) ([]int, error) {
+		calls.Add(1)
+		return nil, errTimeout
+	}}
+
+	client := NewRetryingClient(Client{Live: live}, fastRetries)
+
+	// WHEN
+	_, err := client.Live.StoryIds(context.Background(), "newstories.json")
+
+	// THEN
+	if !errors.Is(err, errTimeout) {
+		t.Errorf("error of last attempt should be returned but got %v", err)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts but got %d", calls.Load())
+	}
+}
+
+func TestRetryingClientShouldNotRetryPermanentFailures(t *testing.T) {
+	testCases := []struct{
+		name string
+		err error
+	}{
+		{name: "client error", err: &StatusError{Path: "item/1.json", StatusCode: http.StatusNotFound}},
+		{name: "malformed answer", err: errors.New("could not decode answer")},
+		{name: "cancelled request", err: &url.Error{Op: "Get", URL: "https://hacker-news.firebaseio.com/v0/item/1.json", Err: context.Canceled}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// GIVEN
+			var calls atomic.Int32
+			items := MockItemsService{MockedGet: func(id int) (*hn.Item, error) {
+				calls.Add(1)
+				return nil, testCase.err
+			}}
+
+			client := NewRetryingClient(Client{Items: items}, fastRetries)
+
+			// WHEN
+			_, err := client.Items.Get(context.Background(), 1)
+
+			// THEN
+			if err != testCase.err {
+				t.Errorf("expected error %v but got %v", testCase.err, err)
+			}
+
+			if calls.Load() != 1 {
+				t.Errorf("permanent failure should not be retried but got %d attempts", calls.Load())
+			}
+		})
+	}
+}
+
+func TestRetryingClientShouldNotRetryPastDeadline(t *testing.T) {
+	// GIVEN
+	var calls atomic.Int32
+	items := MockItemsService{MockedGet: func(id int) (*hn.Item, error) {
+		calls.Add(1)
+		return nil, errUnavailable
+	}}
+
+	client := NewRetryingClient(Client{Items: items}, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Second * 2})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond * 100)
+	defer cancel()
+
+	// WHEN
+	start := time.Now()
+	_, err := client.Items.Get(ctx, 1)
+
+	// THEN
+	if err != errUnavailable {
+		t.Errorf("error of last attempt should be returned but got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Millisecond * 100 {
+		t.Errorf("retry which cannot happen before deadline should not be waited for, but took %v", elapsed)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("expected a single attempt but got %d", calls.Load())
+	}
+}
+
+func TestRetryingClientShouldStopRetryingOnceCancelled(t *testing.T) {
+	// GIVEN
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	items := MockItemsService{MockedGet: func(id int) (*hn.Item, error) {
+		calls.Add(1)
+		time.AfterFunc(time.Millisecond * 20, cancel)
+		return nil, errUnavailable
+	}}
+
+	client := NewRetryingClient(Client{Items: items}, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Second})
+
+	// WHEN
+	start := time.Now()
+	_, err := client.Items.Get(ctx, 1)
+
+	// THEN
+	if err != errUnavailable {
+		t.Errorf("error of last attempt should be returned but got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Millisecond * 500 {
+		t.Errorf("backoff should be interrupted by cancellation, but took %v", elapsed)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("expected a single attempt but got %d", calls.Load())
+	}
+}
+
+func TestRetryBackoffShouldGrowExponentiallyUpToMaxWithJitter(t *testing.T) {
+	// GIVEN
+	policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Millisecond * 100, MaxBackoff: time.Second}
+	expectedBackoffs := []time.Duration{100, 200, 400, 800, 1000, 1000}
+
+	for i, expected := range expectedBackoffs {
+		expected *= time.Millisecond
+
+		// WHEN
+		backoff := policy.backoff(uint32(i + 1))
+
+		// THEN
+		if backoff < expected / 2 || backoff > expected {
+			t.Errorf("backoff after %d failed attempts should be between %v and %v but was %v", i + 1, expected / 2, expected, backoff)
+		}
+	}
+}
@@ -1,58 +1,73 @@
 package users
 
 import (
+	"context"
+	"sync/atomic"
 	"time"
 
 	"hackernews/server/cache"
+	"hackernews/server/upstream"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	hn "github.com/peterhellberg/hn"
 )
 
+var tracer = otel.Tracer("hackernews/server/users")
+
 type hackernewsUserProxy struct {
-	hnClient hn.Client
+	hnClient upstream.Client
 	cache cache.Cache[string, *User]
 }
 
-func NewHackernewsUserProxy(client hn.Client, cache cache.Cache[string, *User]) (UserService) {
+func NewHackernewsUserProxy(client upstream.Client, cache cache.Cache[string, *User]) (UserService) {
 	return &hackernewsUserProxy{
 		hnClient: client,
 		cache: cache,
 	}
 }
 
-func (us *hackernewsUserProxy) GetUserInfo(nickname string) (*User, error) {
+func (us *hackernewsUserProxy) GetUserInfo(ctx context.Context, nickname string) (*User, error) {
 	if nickname == "" {
 		return nil, status.Error(codes.InvalidArgument, "user nickname is required to get user info")
 	}
 
-	userFromCache, userIsCached := us.cache.Get(nickname)
-
-	if userIsCached {
-		return userFromCache, nil
-	} else {
-		user, err := us.fetchUserDetails(nickname)
-
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "error occurred while fetching user '%s' details. Cause: %v", nickname, err)
-		}
+	ctx, span := tracer.Start(ctx, "users.LookupUser", trace.WithAttributes(attribute.String("hackernews.user.nickname", nickname)))
+	defer span.End()
 
-		us.cache.Add(nickname, user)
+	// concurrent requests of a same missing user share a single fetch, traced within the request which started it and
+	// kept running as long as any of them waits for it
+	var fetched atomic.Bool
+	user, err := us.cache.GetOrLoad(ctx, nickname, func(ctx context.Context) (*User, error) {
+		fetched.Store(true)
+		return us.fetchUserDetails(ctx, nickname)
+	})
+	span.SetAttributes(attribute.Bool("hackernews.cache.hit", !fetched.Load()))
 
-		return user, nil
+	if err != nil {
+		return nil, upstream.ErrorStatus(err, "error occurred while fetching user '%s' details. Cause: %v", nickname, err)
 	}
+
+	return user, nil
 }
 
-func (us *hackernewsUserProxy) fetchUserDetails(nickname string) (*User, error) {
+func (us *hackernewsUserProxy) fetchUserDetails(ctx context.Context, nickname string) (*User, error) {
 	if nickname == "" {
 		return nil, status.Error(codes.InvalidArgument, "user nickname must be provided in order to fetch user details")
 	}
 
-	userInfo, err := us.hnClient.User(nickname)
+	ctx, span := tracer.Start(ctx, "hn.User", trace.WithAttributes(attribute.String("hackernews.user.nickname", nickname)))
+	defer span.End()
+
+	userInfo, err := us.hnClient.Users.Get(ctx, nickname)
 	
 	if err != nil {
+		span.SetStatus(otelcodes.Error, err.Error())
 		return nil, err
   	} else if us.userNotFound(userInfo) {
 		return nil, nil
@@ -1,31 +1,56 @@
 package users
 
 import (
+	"context"
 	"errors"
 	"hackernews/server/cache"
+	"hackernews/server/upstream"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	hn "github.com/peterhellberg/hn"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 type MockHnUserService struct {
 	MockedGet func(id string) (*hn.User, error)
 }
 
-func (m MockHnUserService) Get(id string) (*hn.User, error) {
+func (m MockHnUserService) Get(_ context.Context, id string) (*hn.User, error) {
 	return m.MockedGet(id)
 }
 
+var spanExporter = tracetest.NewInMemoryExporter()
+var tracingSetUp sync.Once
+
+// Records spans of the users package, which only binds to the first global tracer provider
+func recordSpans() *tracetest.InMemoryExporter {
+	tracingSetUp.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+	})
+	spanExporter.Reset()
+
+	return spanExporter
+}
+
 func TestGetUserInfoShouldErrorIfNicknameEmpty(t *testing.T) {
 	// GIVEN
-	var client hn.Client = hn.Client{}
-	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](10)
+	var client upstream.Client = upstream.Client{}
+	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](time.Minute)
 	var service UserService = NewHackernewsUserProxy(client, userCache)
 
     nickname := ""
 
 	// WHEN
-	_, err := service.GetUserInfo(nickname)
+	_, err := service.GetUserInfo(context.Background(), nickname)
 
 	// THEN
 	if err == nil {
@@ -35,15 +60,15 @@ func TestGetUserInfoShouldErrorIfNicknameEmpty(t *testing.T) {
 
 func TestGetUserInfoShouldGetUserFromCache(t *testing.T) {
 	// GIVEN
-    var client hn.Client = hn.Client{}
-	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](10)
+    var client upstream.Client = upstream.Client{}
+	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](time.Minute)
 	var service UserService = NewHackernewsUserProxy(client, userCache)
 
 	nickname := "antwan"
 	userCache.Add(nickname, &User{})
 
 	// WHEN
-	user, err := service.GetUserInfo(nickname)
+	user, err := service.GetUserInfo(context.Background(), nickname)
 
 	// THEN
 	if err != nil {
@@ -55,15 +80,15 @@ func TestGetUserInfoShouldGetUserFromCache(t *testing.T) {
 
 func TestGetUserInfoShouldGetUserFromCacheEvenIfNil(t *testing.T) {
     // GIVEN
-	var client hn.Client = hn.Client{}
-	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](10)
+	var client upstream.Client = upstream.Client{}
+	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](time.Minute)
 	var service UserService = NewHackernewsUserProxy(client, userCache)
 
 	nickname := "antwan"
 	userCache.Add(nickname, nil)
 
 	// WHEN
-	user, err := service.GetUserInfo(nickname)
+	user, err := service.GetUserInfo(context.Background(), nickname)
 
 	// THEN
 	if err != nil {
@@ -86,14 +111,14 @@ func TestGetUserInfoShouldFetchUserFromHn(t *testing.T) {
 		return &hnUser, nil
 	}
 
-	var client hn.Client = hn.Client{Users: mockUserService}
-	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](10)
+	var client upstream.Client = upstream.Client{Users: mockUserService}
+	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](time.Minute)
 	var service UserService = NewHackernewsUserProxy(client, userCache)
 
 	nickname := "antwan"
 
 	// WHEN
-	user, err := service.GetUserInfo(nickname)
+	user, err := service.GetUserInfo(context.Background(), nickname)
 
 	// THEN
 	if err != nil {
@@ -115,14 +140,14 @@ func TestGetUserInfoShouldFetchUserFromHnEvenIfUserNotFound(t *testing.T) {
 		return &hn.User{}, nil
 	}
 	
-	var client hn.Client = hn.Client{Users: mockUserService}
-	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](10)
+	var client upstream.Client = upstream.Client{Users: mockUserService}
+	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](time.Minute)
 	var service UserService = NewHackernewsUserProxy(client, userCache)
 
 	nickname := "antwan"
 
 	// WHEN
-	user, err := service.GetUserInfo(nickname)
+	user, err := service.GetUserInfo(context.Background(), nickname)
 
 	// THEN
 	if err != nil {
@@ -147,14 +172,14 @@ func TestGetUserInfoShouldReturnErrorIfFetchFails(t *testing.T) {
 		return nil, errors.New("fetch user info fail")
 	}
 	
-	var client hn.Client = hn.Client{Users: mockUserService}
-	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](10)
+	var client upstream.Client = upstream.Client{Users: mockUserService}
+	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](time.Minute)
 	var service UserService = NewHackernewsUserProxy(client, userCache)
 
 	nickname := "antwan"
 
 	// WHEN
-	user, err := service.GetUserInfo(nickname)
+	user, err := service.GetUserInfo(context.Background(), nickname)
 
 	// THEN
 	if err == nil {
@@ -167,4 +192,215 @@ func TestGetUserInfoShouldReturnErrorIfFetchFails(t *testing.T) {
 	if userExists {
 		t.Error("user should not exist in cache")
 	}
-}
\ No newline at end of file
+}
+func TestGetUserInfoShouldFetchUserOnceForConcurrentRequests(t *testing.T) {
+	// GIVEN
+	var fetchCount atomic.Int32
+	release := make(chan struct{})
+
+	mockUserService := MockHnUserService{}
+	mockUserService.MockedGet = func(id string) (*hn.User, error) {
+		fetchCount.Add(1)
+		<-release
+		return &hn.User{ID: id, Karma: 123}, nil
+	}
+
+	var client upstream.Client = upstream.Client{Users: mockUserService}
+	var userCache cache.Cache[string, *User] = cache.NewTimeToLiveCache[string, *User](time.Minute)
+	var service UserService = NewHackernewsUserProxy(client, userCache)
+
+	nickname := "antwan"
+
+	// WHEN
+	var requests sync.WaitGroup
+	for range 20 {
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			if user, err := service.GetUserInfo(context.Background(), nickname); err != nil || user == nil {
+				t.Errorf("user should have been fetched but got error: %v", err)
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+	requests.Wait()
+
+	// THEN
+	if fetchCount.Load() != 1 {
+		t.Errorf("expected a single fetch of user but got %d", fetchCount.Load())
+	}
+}
+
+func TestGetUserInfoShouldTraceWhetherUserWasCached(t *testing.T) {
+	// GIVEN
+	spans := recordSpans()
+
+	mockUserService := MockHnUserService{}
+	mockUserService.MockedGet = func(id string) (*hn.User, error) {
+		return &hn.User{ID: id, Karma: 1}, nil
+	}
+
+	userCache := cache.NewTimeToLiveCache[string, *User](time.Minute)
+	defer userCache.Close()
+	service := NewHackernewsUserProxy(upstream.Client{Users: mockUserService}, userCache)
+
+	// WHEN
+	service.GetUserInfo(context.Background(), "pg")
+	service.GetUserInfo(context.Background(), "pg")
+
+	// THEN
+	var cacheHits []bool
+	var userFetches []tracetest.SpanStub
+
+	for _, span := range spans.GetSpans() {
+		switch span.Name {
+		case "users.LookupUser":
+			for _, keyValue := range span.Attributes {
+				if keyValue.Key == "hackernews.cache.hit" {
+					cacheHits = append(cacheHits, keyValue.Value.AsBool())
+				}
+			}
+		case "hn.User":
+			userFetches = append(userFetches, span)
+		}
+	}
+
+	if len(cacheHits) != 2 || cacheHits[0] || !cacheHits[1] {
+		t.Errorf("expected a cache miss then a cache hit but got %v", cacheHits)
+	}
+
+	if len(userFetches) != 1 {
+		t.Errorf("expected a single user fetch span but got %d", len(userFetches))
+	}
+}
+
+func TestGetUserInfoShouldNotRequestHnOnceCancelled(t *testing.T) {
+	// GIVEN
+	var userRequests atomic.Int32
+	hnApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userRequests.Add(1)
+		w.Write([]byte(`{"id": "pg"}`))
+	}))
+	defer hnApi.Close()
+
+	baseUrl, _ := url.Parse(hnApi.URL + "/v0/")
+
+	userCache := cache.NewTimeToLiveCache[string, *User](time.Minute)
+	defer userCache.Close()
+	service := NewHackernewsUserProxy(upstream.NewClient(hnApi.Client(), baseUrl), userCache)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// WHEN
+	_, err := service.GetUserInfo(ctx, "pg")
+
+	// THEN
+	if err == nil {
+		t.Error("cancelled request should fail")
+	}
+
+	if userRequests.Load() != 0 {
+		t.Errorf("HackerNews should not be requested once request is cancelled, but was %d times", userRequests.Load())
+	}
+
+	if _, isCached := userCache.Get("pg"); isCached {
+		t.Error("failed lookup should not be cached")
+	}
+}
+
+func TestGetUserInfoShouldKeepFetchingForConcurrentRequestsWhenFirstOneIsCancelled(t *testing.T) {
+	// GIVEN
+	fetchStarted := make(chan struct{}, 1)
+	release := make(chan struct{})
+	hnApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetchStarted <- struct{}{}
+		select {
+		case <-release:
+			w.Write([]byte(`{"id": "pg", "karma": 155111}`))
+		case <-r.Context().Done():
+		}
+	}))
+	defer hnApi.Close()
+
+	baseUrl, _ := url.Parse(hnApi.URL + "/v0/")
+
+	userCache := cache.NewTimeToLiveCache[string, *User](time.Minute)
+	defer userCache.Close()
+	service := NewHackernewsUserProxy(upstream.NewClient(hnApi.Client(), baseUrl), userCache)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := service.GetUserInfo(firstCtx, "pg")
+		firstErr <- err
+	}()
+	<-fetchStarted
+
+	type result struct {
+		user *User
+		err error
+	}
+	second := make(chan result)
+	go func() {
+		user, err := service.GetUserInfo(context.Background(), "pg")
+		second <- result{user, err}
+	}()
+	time.Sleep(time.Millisecond * 20)
+
+	// WHEN
+	cancelFirst()
+
+	// THEN
+	if err := <-firstErr; err == nil {
+		t.Error("cancelled request should fail")
+	}
+
+	close(release)
+	if actual := <-second; actual.err != nil || actual.user == nil || actual.user.Karma != 155111 {
+		t.Errorf("concurrent request should get the user despite the first one being cancelled, but got %+v, %v", actual.user, actual.err)
+	}
+
+	if len(fetchStarted) != 0 {
+		t.Error("user should have been fetched once")
+	}
+}
+
+func TestGetUserInfoShouldNotRetryPastRequestDeadline(t *testing.T) {
+	// GIVEN
+	var userRequests atomic.Int32
+	hnApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userRequests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer hnApi.Close()
+
+	baseUrl, _ := url.Parse(hnApi.URL + "/v0/")
+	retryPolicy := upstream.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Second}
+
+	userCache := cache.NewTimeToLiveCache[string, *User](time.Minute)
+	defer userCache.Close()
+	service := NewHackernewsUserProxy(upstream.NewRetryingClient(upstream.NewClient(hnApi.Client(), baseUrl), retryPolicy), userCache)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond * 300)
+	defer cancel()
+
+	// WHEN
+	start := time.Now()
+	_, err := service.GetUserInfo(ctx, "pg")
+
+	// THEN
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("expected the error answered by HackerNews API rather than the deadline being exceeded but got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Millisecond * 250 {
+		t.Errorf("request should give up retrying when the backoff exceeds its deadline but took %v", elapsed)
+	}
+
+	if userRequests.Load() != 1 {
+		t.Errorf("expected a single request to HackerNews API but got %d", userRequests.Load())
+	}
+}
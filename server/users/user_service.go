@@ -1,5 +1,7 @@
 package users
 
+import "context"
+
 type UserService interface {
-	GetUserInfo(nickname string) (*User, error)
-}
\ No newline at end of file
+	GetUserInfo(ctx context.Context, nickname string) (*User, error)
+}
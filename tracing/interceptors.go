@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const instrumentationName string = "hackernews/tracing"
+
+// Starts a server span for each unary RPC, child of the trace context sent by the client if any
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span := startServerSpan(ctx, info.FullMethod)
+		defer span.End()
+
+		response, err := handler(ctx, request)
+		endRpcSpan(span, err)
+
+		return response, err
+	}
+}
+
+// Starts a server span for each streaming RPC, lasting until the stream ends
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startServerSpan(stream.Context(), info.FullMethod)
+		defer span.End()
+
+		err := handler(server, &tracedServerStream{ServerStream: stream, ctx: ctx})
+		endRpcSpan(span, err)
+
+		return err
+	}
+}
+
+// Starts a client span for each unary RPC and sends its trace context to the server
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, request any, reply any, conn *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := startClientSpan(ctx, method)
+		defer span.End()
+
+		err := invoker(ctx, method, request, reply, conn, opts...)
+		endRpcSpan(span, err)
+
+		return err
+	}
+}
+
+// Starts a client span for each streaming RPC and sends its trace context to the server.
+// The span ends once the stream is open, receiving messages not being traced
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, conn *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := startClientSpan(ctx, method)
+		defer span.End()
+
+		stream, err := streamer(ctx, desc, conn, method, opts...)
+		endRpcSpan(span, err)
+
+		return stream, err
+	}
+}
+
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(incoming))
+
+	return otel.Tracer(instrumentationName).Start(ctx, spanName(fullMethod), trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(rpcAttributes(fullMethod)...))
+}
+
+func startClientSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, spanName(fullMethod), trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(rpcAttributes(fullMethod)...))
+
+	outgoing, _ := metadata.FromOutgoingContext(ctx)
+	outgoing = outgoing.Copy()
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(outgoing))
+
+	return metadata.NewOutgoingContext(ctx, outgoing), span
+}
+
+func endRpcSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int64("rpc.grpc.status_code", int64(code)))
+
+	if err != nil {
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+}
+
+// Span name of a gRPC full method, e.g. hackernews.HnService/GetTopStories for /hackernews.HnService/GetTopStories
+func spanName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	service, method, _ := strings.Cut(spanName(fullMethod), "/")
+
+	return []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	}
+}
+
+// Server stream whose context carries the RPC span
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// Carries trace context through gRPC metadata
+type metadataCarrier metadata.MD
+
+func (carrier metadataCarrier) Get(key string) string {
+	values := metadata.MD(carrier).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (carrier metadataCarrier) Set(key string, value string) {
+	metadata.MD(carrier).Set(key, value)
+}
+
+func (carrier metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(carrier))
+	for key := range carrier {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
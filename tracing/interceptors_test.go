@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestInterceptorsShouldPropagateTraceFromClientToServer(t *testing.T) {
+	// GIVEN
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(UnaryServerInterceptor()))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// WHEN
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+
+	// THEN
+	if err != nil {
+		t.Fatalf("no error should be met but got: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected a client and a server span but got %d spans", len(spans))
+	}
+
+	spansByKind := map[trace.SpanKind]sdktrace.ReadOnlySpan{}
+	for _, span := range spans {
+		spansByKind[span.SpanKind()] = span
+	}
+
+	clientSpan, serverSpan := spansByKind[trace.SpanKindClient], spansByKind[trace.SpanKindServer]
+	if clientSpan == nil || serverSpan == nil {
+		t.Fatal("expected a client and a server span")
+	}
+
+	if serverSpan.Name() != "grpc.health.v1.Health/Check" {
+		t.Errorf("expected span named after the RPC but got '%s'", serverSpan.Name())
+	}
+
+	if serverSpan.Parent().SpanID() != clientSpan.SpanContext().SpanID() || serverSpan.SpanContext().TraceID() != clientSpan.SpanContext().TraceID() {
+		t.Error("server span should be a child of the client span, in the same trace")
+	}
+}
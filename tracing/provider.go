@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporters spans can be sent to
+const (
+	NoExporter string = "none"
+	StdoutExporter string = "stdout"
+	OtlpExporter string = "otlp"
+)
+
+func IsExporter(name string) bool {
+	return name == NoExporter || name == StdoutExporter || name == OtlpExporter
+}
+
+// Installs the global tracer provider of serviceName, exporting spans through exporter: printed to stdout, or sent over gRPC
+// to the OTLP collector at otlpEndpoint. With NoExporter, spans are not recorded but incoming trace context is still propagated.
+// The returned function flushes pending spans and must be called before exiting
+func Setup(exporter string, otlpEndpoint string, serviceName string) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+
+	switch exporter {
+	case NoExporter:
+		return func(context.Context) error { return nil }, nil
+	case StdoutExporter:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case OtlpExporter:
+		spanExporter, err = otlptracegrpc.New(context.Background(), otlptracegrpc.WithEndpoint(otlpEndpoint), otlptracegrpc.WithInsecure())
+	default:
+		return nil, fmt.Errorf("unknown traces exporter '%s'", exporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s traces exporter: %w", exporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}